@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/model-cli/pkg/config"
+)
+
+// apiKeysFileName is the name of the file, within the Model CLI state
+// directory, that holds the API keys saved with run --save-key.
+const apiKeysFileName = "api-keys.json"
+
+func apiKeysPath() string {
+	return filepath.Join(config.Dir(), apiKeysFileName)
+}
+
+// loadAPIKeys reads the saved API keys, keyed by backend. A missing file
+// yields no keys.
+func loadAPIKeys() (map[string]string, error) {
+	data, err := os.ReadFile(apiKeysPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("unable to read saved API keys: %w", err)
+	}
+	keys := map[string]string{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid API keys file %s: %w", apiKeysPath(), err)
+	}
+	return keys, nil
+}
+
+// saveAPIKeys writes the saved API keys. The file is only readable by the
+// user, and is replaced atomically like the settings file.
+func saveAPIKeys(keys map[string]string) error {
+	data, err := json.MarshalIndent(keys, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error marshaling API keys: %w", err)
+	}
+	if err := os.MkdirAll(config.Dir(), 0o700); err != nil {
+		return fmt.Errorf("unable to create configuration directory: %w", err)
+	}
+	// Temporary files are created with mode 0600.
+	tmp, err := os.CreateTemp(config.Dir(), apiKeysFileName+".*")
+	if err != nil {
+		return fmt.Errorf("unable to save API keys: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to save API keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to save API keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), apiKeysPath()); err != nil {
+		return fmt.Errorf("unable to save API keys: %w", err)
+	}
+	return nil
+}
+
+// saveAPIKey saves the API key of backend, replacing any earlier one.
+func saveAPIKey(backend, apiKey string) error {
+	keys, err := loadAPIKeys()
+	if err != nil {
+		return err
+	}
+	keys[backend] = apiKey
+	return saveAPIKeys(keys)
+}
+
+// removeAPIKey removes the saved API key of backend. It returns false if no
+// key was saved.
+func removeAPIKey(backend string) (bool, error) {
+	keys, err := loadAPIKeys()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := keys[backend]; !ok {
+		return false, nil
+	}
+	delete(keys, backend)
+	return true, saveAPIKeys(keys)
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	cliconfig "github.com/docker/cli/cli/config"
+)
+
+func TestSavedAPIKeys(t *testing.T) {
+	dir := cliconfig.Dir()
+	cliconfig.SetDir(t.TempDir())
+	t.Cleanup(func() { cliconfig.SetDir(dir) })
+	t.Setenv("OPENAI_API_KEY", "")
+
+	if _, err := ensureAPIKey("openai"); err == nil {
+		t.Fatal("ensureAPIKey() succeeded without a key")
+	}
+	if err := saveAPIKey("openai", "sk-saved"); err != nil {
+		t.Fatalf("saveAPIKey() error = %v", err)
+	}
+	info, err := os.Stat(apiKeysPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("API keys file has mode %o, want 600", perm)
+	}
+	if apiKey, err := ensureAPIKey("openai"); err != nil || apiKey != "sk-saved" {
+		t.Errorf("ensureAPIKey() = %q, %v, want the saved key", apiKey, err)
+	}
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	if apiKey, _ := ensureAPIKey("openai"); apiKey != "sk-env" {
+		t.Errorf("ensureAPIKey() = %q, want the key from the environment", apiKey)
+	}
+
+	if removed, err := removeAPIKey("openai"); err != nil || !removed {
+		t.Fatalf("removeAPIKey() = %v, %v, want true", removed, err)
+	}
+	if removed, err := removeAPIKey("openai"); err != nil || removed {
+		t.Errorf("removeAPIKey() = %v, %v after removing the key, want false", removed, err)
+	}
+}
@@ -7,6 +7,11 @@ import (
 	"os"
 	"slices"
 	"strings"
+
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/config"
+	"github.com/docker/model-distribution/types"
+	"github.com/spf13/cobra"
 )
 
 // ValidBackends is a map of valid backends
@@ -15,6 +20,42 @@ var ValidBackends = map[string]bool{
 	"openai":    true,
 }
 
+// llamaCppArchitectures are the model architectures supported by the llama.cpp
+// backend, as reported in the general.architecture field of GGUF files.
+var llamaCppArchitectures = []string{
+	"arcee", "arctic", "arwkv7", "baichuan", "bailingmoe", "bert", "bitnet", "bloom",
+	"chameleon", "chatglm", "codeshell", "cohere2", "command-r", "dbrx", "deci",
+	"deepseek", "deepseek2", "dots1", "ernie4_5", "exaone", "falcon", "falcon-h1",
+	"gemma", "gemma2", "gemma3", "gemma3n", "glm4", "gpt2", "gptj", "gptneox",
+	"granite", "granitemoe", "grok", "hunyuan-moe", "internlm2", "jais",
+	"jina-bert-v2", "lfm2", "llama", "llama4", "mamba", "mamba2", "minicpm",
+	"minicpm3", "mpt", "nemotron", "neo-bert", "nomic-bert", "nomic-bert-moe",
+	"olmo", "olmo2", "olmoe", "openelm", "orion", "phi2", "phi3", "phimoe", "plamo",
+	"plm", "qwen", "qwen2", "qwen2moe", "qwen2vl", "qwen3", "qwen3moe", "refact",
+	"rwkv6", "rwkv6qwen2", "rwkv7", "smollm3", "stablelm", "starcoder",
+	"starcoder2", "t5", "t5encoder", "xverse",
+}
+
+// checkModelSupported returns an error if the model is known not to run on the
+// specified local backend. Models with an unreported format or architecture are
+// given the benefit of the doubt.
+func checkModelSupported(backend string, m desktop.Model) error {
+	if backend == "" {
+		backend = desktop.DefaultBackend
+	}
+	if backend != "llama.cpp" {
+		return nil
+	}
+	if m.Config.Format != "" && m.Config.Format != types.FormatGGUF {
+		return fmt.Errorf("backend %s doesn't support the %s model format (only %s)", backend, m.Config.Format, types.FormatGGUF)
+	}
+	if arch := m.Config.Architecture; arch != "" && !slices.Contains(llamaCppArchitectures, arch) {
+		return fmt.Errorf("backend %s doesn't support the %s architecture.\n"+
+			"If the backend has been updated to support it, use --skip-architecture-check", backend, arch)
+	}
+	return nil
+}
+
 // validateBackend checks if the provided backend is valid
 func validateBackend(backend string) error {
 	if !ValidBackends[backend] {
@@ -24,14 +65,40 @@ func validateBackend(backend string) error {
 	return nil
 }
 
-// ensureAPIKey retrieves the API key if needed
+// resolveBackend returns the backend to use, falling back to the configured
+// default backend if the --backend flag was not specified.
+func resolveBackend(cmd *cobra.Command, backend string) (string, error) {
+	if cmd.Flags().Changed("backend") {
+		return backend, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	if cfg.DefaultBackend != "" {
+		return cfg.DefaultBackend, nil
+	}
+	return backend, nil
+}
+
+// ensureAPIKey retrieves the API key if needed, from the environment or else
+// from the keys saved with run --save-key.
 func ensureAPIKey(backend string) (string, error) {
 	if backend == "openai" {
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			return "", errors.New("OPENAI_API_KEY environment variable is required when using --backend=openai")
+		if err := ensureOnline("use the openai backend"); err != nil {
+			return "", err
+		}
+		if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+			return apiKey, nil
+		}
+		keys, err := loadAPIKeys()
+		if err != nil {
+			return "", err
+		}
+		if apiKey := keys[backend]; apiKey != "" {
+			return apiKey, nil
 		}
-		return apiKey, nil
+		return "", errors.New("OPENAI_API_KEY environment variable is required when using --backend=openai, unless a key was saved with --save-key")
 	}
 	return "", nil
 }
@@ -0,0 +1,511 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/klauspost/compress/zstd"
+)
+
+// A model bundle is a tar archive holding several models, for moving them
+// between hosts in a single file. It contains an index of the models and the
+// blobs of their manifests, configs, and layers, each stored once even if
+// several models share it. The index is written last, once the digests of
+// all manifests are known:
+//
+//	blobs/sha256/<hex>
+//	index.json
+const bundleIndexName = "index.json"
+
+// bundleIndex lists the models in a bundle.
+type bundleIndex struct {
+	Models []bundleModel `json:"models"`
+}
+
+// bundleModel is a model in a bundle, identified by the digest of its
+// manifest.
+type bundleModel struct {
+	Reference string `json:"reference"`
+	Manifest  string `json:"manifest"`
+}
+
+// Compression formats of bundles. Compressed bundles are recognized by their
+// magic number when they are read.
+const (
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compressWriter returns a writer that compresses what is written to it to
+// w in the given format, or that writes it to w as is if format is empty.
+func compressWriter(w io.Writer, format string) (io.WriteCloser, error) {
+	switch format {
+	case "":
+		return nopWriteCloser{w}, nil
+	case compressionGzip:
+		return gzip.NewWriter(w), nil
+	case compressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression format %q (must be %s or %s)", format, compressionGzip, compressionZstd)
+	}
+}
+
+// bundleCompression returns the compression format of the bundle read by r,
+// or an empty string if it isn't compressed.
+func bundleCompression(r io.ReaderAt) string {
+	magic := make([]byte, len(zstdMagic))
+	n, _ := r.ReadAt(magic, 0)
+	switch {
+	case bytes.HasPrefix(magic[:n], gzipMagic):
+		return compressionGzip
+	case bytes.HasPrefix(magic[:n], zstdMagic):
+		return compressionZstd
+	}
+	return ""
+}
+
+// decompressReader returns a reader that decompresses r in the given format.
+func decompressReader(r io.Reader, format string) (io.ReadCloser, error) {
+	switch format {
+	case compressionGzip:
+		return gzip.NewReader(r)
+	case compressionZstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	}
+	return io.NopCloser(r), nil
+}
+
+func bundleBlobName(digest v1.Hash) string {
+	return path.Join("blobs", digest.Algorithm, digest.Hex)
+}
+
+// bundleWriter writes a bundle.
+type bundleWriter struct {
+	tw      *tar.Writer
+	written map[v1.Hash]bool
+}
+
+func newBundleWriter(w io.Writer) *bundleWriter {
+	return &bundleWriter{tw: tar.NewWriter(w), written: make(map[v1.Hash]bool)}
+}
+
+func (b *bundleWriter) writeIndex(index bundleIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := b.tw.WriteHeader(&tar.Header{Name: bundleIndexName, Mode: 0644, Size: int64(len(data))}); err != nil {
+		return err
+	}
+	_, err = b.tw.Write(data)
+	return err
+}
+
+// has reports whether the blob with the given digest was already written.
+func (b *bundleWriter) has(digest v1.Hash) bool {
+	return b.written[digest]
+}
+
+// writeBlob writes a blob, unless it was already written.
+func (b *bundleWriter) writeBlob(digest v1.Hash, size int64, r io.Reader) error {
+	if b.written[digest] {
+		return nil
+	}
+	if err := b.tw.WriteHeader(&tar.Header{Name: bundleBlobName(digest), Mode: 0644, Size: size}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(b.tw, r); err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", digest, err)
+	}
+	b.written[digest] = true
+	return nil
+}
+
+// addModelArchive writes the blobs and the manifest of a model archive, in
+// the format produced by the model runner's save endpoint, and returns the
+// digest of the manifest. The number of bytes of the blobs that are written
+// is passed to progress as they are copied.
+func (b *bundleWriter) addModelArchive(r io.Reader, progress func(int)) (v1.Hash, error) {
+	tr := tar.NewReader(r)
+	var manifest []byte
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return v1.Hash{}, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if name == "manifest.json" {
+			if manifest, err = io.ReadAll(tr); err != nil {
+				return v1.Hash{}, err
+			}
+			continue
+		}
+		dir, hex := path.Split(name)
+		alg := path.Base(dir)
+		if path.Dir(path.Clean(dir)) != "blobs" {
+			continue
+		}
+		digest, err := v1.NewHash(alg + ":" + hex)
+		if err != nil {
+			return v1.Hash{}, fmt.Errorf("invalid blob %s in model archive: %w", hdr.Name, err)
+		}
+		if b.has(digest) {
+			continue
+		}
+		if err := b.writeBlob(digest, hdr.Size, &countingReader{r: tr, count: progress}); err != nil {
+			return v1.Hash{}, err
+		}
+	}
+	if manifest == nil {
+		return v1.Hash{}, errors.New("model archive has no manifest.json")
+	}
+	digest, _, err := v1.SHA256(bytes.NewReader(manifest))
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	if err := b.writeBlob(digest, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+		return v1.Hash{}, err
+	}
+	return digest, nil
+}
+
+func (b *bundleWriter) Close() error {
+	return b.tw.Close()
+}
+
+// bundleReader reads the index and blobs of a bundle file. A compressed
+// bundle is decompressed to a temporary file next to it first, since its
+// blobs are read in a different order than they are stored in.
+type bundleReader struct {
+	f       *os.File
+	index   bundleIndex
+	entries map[string]bundleEntry
+	// temp is the name of the temporary file holding the decompressed
+	// bundle, if any.
+	temp string
+}
+
+// bundleEntry is the location of a file's contents in a bundle.
+type bundleEntry struct {
+	offset int64
+	size   int64
+}
+
+func openBundle(name string) (*bundleReader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	b := &bundleReader{f: f, entries: make(map[string]bundleEntry)}
+	if format := bundleCompression(f); format != "" {
+		err := b.decompress(name, format)
+		f.Close()
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("unable to decompress bundle %s: %w", name, err)
+		}
+	}
+	if err := b.scan(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("unable to read bundle %s: %w", name, err)
+	}
+	return b, nil
+}
+
+// decompress decompresses the bundle file name to a temporary file in the
+// same directory and reads from that instead. Unlike the system's temporary
+// directory, which may be in memory, the bundle's directory is known to have
+// room for large files.
+func (b *bundleReader) decompress(name, format string) error {
+	r, err := decompressReader(b.f, format)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-*.tar")
+	if err != nil {
+		return err
+	}
+	b.f, b.temp = tmp, tmp.Name()
+	if _, err := io.Copy(tmp, r); err != nil {
+		return err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	return err
+}
+
+// scan records where the contents of each file in the bundle are, and reads
+// its index.
+func (b *bundleReader) scan() error {
+	// The tar reader seeks over the contents of the files and doesn't read
+	// ahead, so the file's offset is that of the current file's contents.
+	tr := tar.NewReader(b.f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		offset, err := b.f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		b.entries[path.Clean(hdr.Name)] = bundleEntry{offset: offset, size: hdr.Size}
+	}
+	index, ok := b.entries[bundleIndexName]
+	if !ok {
+		return errors.New("not a model bundle: no " + bundleIndexName)
+	}
+	data, err := io.ReadAll(io.NewSectionReader(b.f, index.offset, index.size))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &b.index); err != nil {
+		return fmt.Errorf("invalid %s: %w", bundleIndexName, err)
+	}
+	return nil
+}
+
+// bundleHolds reports whether the bundle file name exists, is compressed in
+// the given format, and holds exactly models, whose manifests have the given
+// digests, with all of their blobs. A compressed bundle is read as a stream,
+// without decompressing it to a file.
+func bundleHolds(name, compression string, models []string, digests []v1.Hash) bool {
+	f, err := os.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if bundleCompression(f) != compression {
+		return false
+	}
+	keep := map[string]bool{bundleIndexName: true}
+	for _, digest := range digests {
+		keep[bundleBlobName(digest)] = true
+	}
+	var files map[string][]byte
+	if compression == "" {
+		files, err = readBundleFiles(f, keep)
+	} else {
+		var r io.ReadCloser
+		if r, err = decompressReader(f, compression); err != nil {
+			return false
+		}
+		defer r.Close()
+		files, err = streamBundleFiles(r, keep)
+	}
+	if err != nil {
+		return false
+	}
+	var index bundleIndex
+	if err := json.Unmarshal(files[bundleIndexName], &index); err != nil || len(index.Models) != len(models) {
+		return false
+	}
+	for i, model := range index.Models {
+		if model.Reference != models[i] || model.Manifest != digests[i].String() {
+			return false
+		}
+		raw, ok := files[bundleBlobName(digests[i])]
+		if !ok {
+			return false
+		}
+		manifest, err := v1.ParseManifest(bytes.NewReader(raw))
+		if err != nil {
+			return false
+		}
+		for _, desc := range manifestBlobs(manifest) {
+			if _, ok := files[bundleBlobName(desc.Digest)]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// readBundleFiles lists the files of an uncompressed bundle, seeking over
+// their contents, and returns them along with the contents of those in keep.
+// The contents of the other files are nil.
+func readBundleFiles(f *os.File, keep map[string]bool) (map[string][]byte, error) {
+	b := &bundleReader{f: f, entries: make(map[string]bundleEntry)}
+	if err := b.scan(); err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte, len(b.entries))
+	for name, entry := range b.entries {
+		files[name] = nil
+		if keep[name] {
+			data, err := io.ReadAll(io.NewSectionReader(f, entry.offset, entry.size))
+			if err != nil {
+				return nil, err
+			}
+			files[name] = data
+		}
+	}
+	return files, nil
+}
+
+// streamBundleFiles is like readBundleFiles for a bundle read as a stream.
+func streamBundleFiles(r io.Reader, keep map[string]bool) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		files[name] = nil
+		if keep[name] {
+			if files[name], err = io.ReadAll(tr); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (b *bundleReader) blob(digest v1.Hash) (*io.SectionReader, error) {
+	entry, ok := b.entries[bundleBlobName(digest)]
+	if !ok {
+		return nil, fmt.Errorf("blob %s is missing from the bundle", digest)
+	}
+	return io.NewSectionReader(b.f, entry.offset, entry.size), nil
+}
+
+// manifest returns the raw and the parsed manifest of a model in the bundle.
+func (b *bundleReader) manifest(model bundleModel) ([]byte, *v1.Manifest, error) {
+	digest, err := v1.NewHash(model.Manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest digest of %s: %w", model.Reference, err)
+	}
+	r, err := b.blob(digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, err := v1.ParseManifest(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest of %s: %w", model.Reference, err)
+	}
+	return raw, manifest, nil
+}
+
+// writeModelArchive writes a model in the archive format accepted by the
+// model runner's load endpoint. Blobs in skip, which the model runner already
+// has, are left out, and the number of bytes of the blobs that are written is
+// passed to progress as they are copied.
+func (b *bundleReader) writeModelArchive(w io.Writer, model bundleModel, skip map[v1.Hash]bool, progress func(int)) error {
+	raw, manifest, err := b.manifest(model)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(w)
+	for _, desc := range manifestBlobs(manifest) {
+		if skip[desc.Digest] {
+			continue
+		}
+		r, err := b.blob(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: bundleBlobName(desc.Digest), Mode: 0644, Size: r.Size()}); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, &countingReader{r: r, count: progress}); err != nil {
+			return err
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0644, Size: int64(len(raw))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(raw); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func (b *bundleReader) Close() error {
+	err := b.f.Close()
+	if b.temp != "" {
+		os.Remove(b.temp)
+	}
+	return err
+}
+
+// manifestBlobs returns the layers and the config of a manifest.
+func manifestBlobs(manifest *v1.Manifest) []v1.Descriptor {
+	return append(slices.Clone(manifest.Layers), manifest.Config)
+}
+
+// countingReader passes the number of bytes read from r to count.
+type countingReader struct {
+	r     io.Reader
+	count func(int)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.count(n)
+	}
+	return n, err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
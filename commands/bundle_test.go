@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/model-distribution/tarball"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestBundle(t *testing.T) {
+	blob := func(content string) (v1.Descriptor, []byte) {
+		t.Helper()
+		digest, size, err := v1.SHA256(bytes.NewReader([]byte(content)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v1.Descriptor{Digest: digest, Size: size}, []byte(content)
+	}
+	shared, sharedContent := blob("shared weights")
+	own, ownContent := blob("own weights")
+	config, configContent := blob(`{"config":{}}`)
+	manifestOf := func(layers ...v1.Descriptor) (v1.Hash, []byte) {
+		t.Helper()
+		raw, err := json.Marshal(v1.Manifest{SchemaVersion: 2, Config: config, Layers: layers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest, _, err := v1.SHA256(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return digest, raw
+	}
+	first, firstManifest := manifestOf(shared)
+	second, secondManifest := manifestOf(shared, own)
+
+	path := filepath.Join(t.TempDir(), "bundle.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newBundleWriter(f)
+	index := bundleIndex{Models: []bundleModel{
+		{Reference: "ai/first:latest", Manifest: first.String()},
+		{Reference: "ai/second:latest", Manifest: second.String()},
+	}}
+	if err := w.writeIndex(index); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []struct {
+		digest  v1.Hash
+		content []byte
+	}{
+		{shared.Digest, sharedContent},
+		{config.Digest, configContent},
+		{first, firstManifest},
+		{shared.Digest, sharedContent},
+		{own.Digest, ownContent},
+		{config.Digest, configContent},
+		{second, secondManifest},
+	} {
+		if err := w.writeBlob(b.digest, int64(len(b.content)), bytes.NewReader(b.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := openBundle(path)
+	if err != nil {
+		t.Fatalf("openBundle() error = %v", err)
+	}
+	defer r.Close()
+	if len(r.index.Models) != 2 || r.index.Models[1] != index.Models[1] {
+		t.Errorf("openBundle() read index %+v, want %+v", r.index, index)
+	}
+	if blobs := len(r.entries) - 1; blobs != 5 {
+		t.Errorf("bundle holds %d blobs, want 5 with the shared ones stored once", blobs)
+	}
+	refs := []string{"ai/first:latest", "ai/second:latest"}
+	if !bundleHolds(path, "", refs, []v1.Hash{first, second}) {
+		t.Error("bundleHolds() = false for the saved models, want true")
+	}
+	if bundleHolds(path, "", refs, []v1.Hash{first, first}) {
+		t.Error("bundleHolds() = true for a different manifest, want false")
+	}
+	if bundleHolds(path, "", refs[:1], []v1.Hash{first}) {
+		t.Error("bundleHolds() = true for a subset of the models, want false")
+	}
+	if bundleHolds(path, compressionZstd, refs, []v1.Hash{first, second}) {
+		t.Error("bundleHolds() = true for an uncompressed bundle when a compressed one is wanted, want false")
+	}
+	if bundleHolds(path+".missing", "", refs, []v1.Hash{first, second}) {
+		t.Error("bundleHolds() = true for a missing file, want false")
+	}
+
+	// A compressed bundle is checked without decompressing it to a file.
+	compressed := filepath.Join(t.TempDir(), "bundle.tar.zst")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf, err := os.Create(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cw, err := compressWriter(zf, compressionZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []io.Closer{cw, zf} {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bundleHolds(compressed, compressionZstd, refs, []v1.Hash{first, second}) {
+		t.Error("bundleHolds() = false for the compressed bundle, want true")
+	}
+	if bundleHolds(compressed, compressionZstd, refs, []v1.Hash{second, first}) {
+		t.Error("bundleHolds() = true for the compressed bundle with other manifests, want false")
+	}
+	if entries, err := os.ReadDir(filepath.Dir(compressed)); err != nil || len(entries) != 1 {
+		t.Errorf("bundleHolds() left files next to the compressed bundle: %v, %v", entries, err)
+	}
+
+	// The archive of the second model leaves out the blobs that were sent
+	// with the first one.
+	var archive bytes.Buffer
+	written := 0
+	skip := map[v1.Hash]bool{shared.Digest: true, config.Digest: true}
+	if err := r.writeModelArchive(&archive, r.index.Models[1], skip, func(n int) { written += n }); err != nil {
+		t.Fatalf("writeModelArchive() error = %v", err)
+	}
+	if written != len(ownContent) {
+		t.Errorf("writeModelArchive() reported %d bytes, want %d", written, len(ownContent))
+	}
+	tr := tarball.NewReader(&archive)
+	var blobs []v1.Hash
+	for {
+		diffID, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		blobs = append(blobs, diffID)
+	}
+	if len(blobs) != 1 || blobs[0] != own.Digest {
+		t.Errorf("archive holds blobs %v, want only %s", blobs, own.Digest)
+	}
+	raw, digest, err := tr.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != second || !bytes.Equal(raw, secondManifest) {
+		t.Errorf("archive holds manifest %s, want %s", digest, second)
+	}
+}
+
+func TestBundleCompression(t *testing.T) {
+	content := []byte("weights")
+	digest, _, err := v1.SHA256(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"", compressionGzip, compressionZstd} {
+		path := filepath.Join(t.TempDir(), "bundle.tar")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cw, err := compressWriter(f, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := newBundleWriter(cw)
+		if err := w.writeBlob(digest, int64(len(content)), bytes.NewReader(content)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.writeIndex(bundleIndex{Models: []bundleModel{{Reference: "ai/model:latest"}}}); err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range []io.Closer{w, cw, f} {
+			if err := c.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r, err := openBundle(path)
+		if err != nil {
+			t.Fatalf("openBundle() of a %q bundle error = %v", format, err)
+		}
+		if got := bundleCompression(r.f); got != "" {
+			t.Errorf("openBundle() of a %q bundle reads a %q file, want it decompressed", format, got)
+		}
+		blob, err := r.blob(digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := io.ReadAll(blob); err != nil || !bytes.Equal(got, content) {
+			t.Errorf("blob of a %q bundle = %q, %v, want %q", format, got, err, content)
+		}
+		temp := r.temp
+		if (temp != "") != (format != "") {
+			t.Errorf("openBundle() of a %q bundle decompressed it to %q", format, temp)
+		}
+		if temp != "" && filepath.Dir(temp) != filepath.Dir(path) {
+			t.Errorf("openBundle() decompressed a %q bundle to %s, want it next to the bundle", format, temp)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if temp != "" {
+			if _, err := os.Stat(temp); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("decompressed %q bundle %s wasn't removed", format, temp)
+			}
+		}
+	}
+}
+
+func TestBundleAddModelArchive(t *testing.T) {
+	layer := []byte("weights")
+	layerDigest, _, err := v1.SHA256(bytes.NewReader(layer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := []byte(`{"schemaVersion":2}`)
+	manifestDigest, _, err := v1.SHA256(bytes.NewReader(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, file := range []struct {
+		name    string
+		content []byte
+	}{
+		{bundleBlobName(layerDigest), layer},
+		{"manifest.json", manifest},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(file.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	w := newBundleWriter(&out)
+	written := 0
+	digest, err := w.addModelArchive(&archive, func(n int) { written += n })
+	if err != nil {
+		t.Fatalf("addModelArchive() error = %v", err)
+	}
+	if digest != manifestDigest {
+		t.Errorf("addModelArchive() = %s, want %s", digest, manifestDigest)
+	}
+	if written != len(layer) {
+		t.Errorf("addModelArchive() reported %d bytes, want %d", written, len(layer))
+	}
+	if !w.has(layerDigest) || !w.has(manifestDigest) {
+		t.Errorf("addModelArchive() didn't write the layer and the manifest")
+	}
+
+	if _, err := w.addModelArchive(bytes.NewReader(nil), func(int) {}); err == nil {
+		t.Errorf("addModelArchive() of an archive without a manifest succeeded")
+	}
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/model-cli/pkg/config"
+)
+
+const (
+	// catalogURL lists the repositories of the ai namespace on Docker Hub,
+	// which holds the Docker Hub model catalog.
+	catalogURL = "https://hub.docker.com/v2/repositories/ai/?page_size=100"
+	// catalogTimeout bounds the time spent querying the catalog, so that
+	// completion stays responsive.
+	catalogTimeout = 2 * time.Second
+	// catalogTTL is how long the cached catalog is used before it is queried
+	// again.
+	catalogTTL = 24 * time.Hour
+)
+
+// cachedCatalog is the on-disk representation of the cached catalog.
+type cachedCatalog struct {
+	Fetched time.Time `json:"fetched"`
+	Models  []string  `json:"models"`
+}
+
+func catalogCachePath() string {
+	return filepath.Join(config.Dir(), "cache", "catalog.json")
+}
+
+// catalogModels returns the models of the Docker Hub model catalog, for
+// completion. The catalog is cached, and a stale copy is used if it can't be
+// queried. It returns no models in offline mode.
+func catalogModels(ctx context.Context) []string {
+	if isOffline() {
+		return nil
+	}
+	var cached cachedCatalog
+	if data, err := os.ReadFile(catalogCachePath()); err == nil {
+		_ = json.Unmarshal(data, &cached)
+	}
+	if time.Since(cached.Fetched) < catalogTTL {
+		return cached.Models
+	}
+	ctx, cancel := context.WithTimeout(ctx, catalogTimeout)
+	defer cancel()
+	models, err := fetchCatalog(ctx)
+	if err != nil {
+		return cached.Models
+	}
+	if data, err := json.Marshal(cachedCatalog{Fetched: time.Now(), Models: models}); err == nil {
+		if err := os.MkdirAll(filepath.Dir(catalogCachePath()), 0o700); err == nil {
+			_ = os.WriteFile(catalogCachePath(), data, 0o644)
+		}
+	}
+	return models
+}
+
+// fetchCatalog queries Docker Hub for the models of the catalog.
+func fetchCatalog(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, catalogURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("querying the model catalog failed with status %s", resp.Status)
+	}
+	var page struct {
+		Results []struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("invalid model catalog response: %w", err)
+	}
+	models := make([]string, 0, len(page.Results))
+	for _, repo := range page.Results {
+		models = append(models, repo.Namespace+"/"+repo.Name)
+	}
+	return models, nil
+}
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/config"
+)
+
+// chatTurn is a single prompt/response exchange within a chat session.
+type chatTurn struct {
+	Prompt   string `json:"prompt"`
+	Response string `json:"response"`
+}
+
+// chatSession records an interactive chat session so that it can be exported
+// and replayed against the same model.
+type chatSession struct {
+	// Model is the model reference used for the session.
+	Model string `json:"model"`
+	// ModelID is the resolved model ID, if it could be determined.
+	ModelID string `json:"model_id,omitempty"`
+	// Backend is the backend used for the session, if one was specified.
+	Backend string `json:"backend,omitempty"`
+	// Turns are the exchanges in the order they occurred.
+	Turns []chatTurn `json:"turns"`
+}
+
+// save writes the session to the specified path.
+func (s *chatSession) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error marshaling session: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("unable to write session file: %w", err)
+	}
+	return nil
+}
+
+// loadChatSession reads a session previously written by save.
+func loadChatSession(path string) (*chatSession, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read session file: %w", err)
+	}
+	var session chatSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %w", path, err)
+	}
+	if session.Model == "" {
+		return nil, fmt.Errorf("invalid session file %s: no model specified", path)
+	}
+	return &session, nil
+}
+
+// history returns the turns of the session as chat messages, so that they can
+// be sent as the context of a subsequent prompt.
+func (s *chatSession) history() []desktop.OpenAIChatMessage {
+	messages := make([]desktop.OpenAIChatMessage, 0, 2*len(s.Turns))
+	for _, turn := range s.Turns {
+		messages = append(messages,
+			desktop.OpenAIChatMessage{Role: "user", Content: turn.Prompt},
+			desktop.OpenAIChatMessage{Role: "assistant", Content: turn.Response},
+		)
+	}
+	return messages
+}
+
+// lastSessionPath returns the path of the file in which the last interactive
+// session with model is persisted for run --continue.
+func lastSessionPath(model string) string {
+	if i := strings.LastIndex(model, "/"); !strings.Contains(model[i+1:], ":") &&
+		!strings.HasPrefix(model, "sha256:") {
+		model += ":latest"
+	}
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(model)
+	return filepath.Join(config.Dir(), "sessions", name+".json")
+}
+
+// saveLast persists the session as the last session with its model.
+func (s *chatSession) saveLast() error {
+	path := lastSessionPath(s.Model)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("unable to create sessions directory: %w", err)
+	}
+	return s.save(path)
+}
+
+// loadLastChatSession loads the last session with model. It returns nil if
+// there is no such session.
+func loadLastChatSession(model string) (*chatSession, error) {
+	session, err := loadChatSession(lastSessionPath(model))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return session, err
+}
+
+// sessionNamePattern restricts the names of sessions kept with run --session,
+// which are used as file names.
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// namedSessionsDir returns the directory in which the sessions kept with run
+// --session are persisted.
+func namedSessionsDir() string {
+	return filepath.Join(config.Dir(), "sessions", "named")
+}
+
+// namedSessionPath returns the path of the file in which the session name is
+// persisted.
+func namedSessionPath(name string) (string, error) {
+	if !sessionNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid session name %q: only letters, digits, '_', '.', and '-' are allowed", name)
+	}
+	return filepath.Join(namedSessionsDir(), name+".json"), nil
+}
+
+// loadNamedChatSession loads the session name. It returns nil if there is no
+// such session.
+func loadNamedChatSession(name string) (*chatSession, error) {
+	path, err := namedSessionPath(name)
+	if err != nil {
+		return nil, err
+	}
+	session, err := loadChatSession(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return session, err
+}
+
+// saveNamed persists the session under name.
+func (s *chatSession) saveNamed(name string) error {
+	path, err := namedSessionPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("unable to create sessions directory: %w", err)
+	}
+	return s.save(path)
+}
+
+// namedSessionInfo describes a session kept with run --session.
+type namedSessionInfo struct {
+	Name    string
+	Model   string
+	Turns   int
+	Updated time.Time
+}
+
+// listNamedChatSessions returns the sessions kept with run --session, sorted
+// by name. Files that aren't valid sessions are skipped.
+func listNamedChatSessions() ([]namedSessionInfo, error) {
+	entries, err := os.ReadDir(namedSessionsDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to list sessions: %w", err)
+	}
+	var sessions []namedSessionInfo
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || !sessionNamePattern.MatchString(name) {
+			continue
+		}
+		session, err := loadChatSession(filepath.Join(namedSessionsDir(), entry.Name()))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, namedSessionInfo{
+			Name:    name,
+			Model:   session.Model,
+			Turns:   len(session.Turns),
+			Updated: info.ModTime(),
+		})
+	}
+	return sessions, nil
+}
+
+// removeNamedChatSession removes the session name.
+func removeNamedChatSession(name string) error {
+	path, err := namedSessionPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no such session: %s", name)
+		}
+		return fmt.Errorf("unable to remove session %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"testing"
+
+	cliconfig "github.com/docker/cli/cli/config"
+)
+
+func TestNamedChatSessions(t *testing.T) {
+	dir := cliconfig.Dir()
+	cliconfig.SetDir(t.TempDir())
+	t.Cleanup(func() { cliconfig.SetDir(dir) })
+
+	if session, err := loadNamedChatSession("debug"); err != nil || session != nil {
+		t.Fatalf("loadNamedChatSession() = %v, %v, want no session", session, err)
+	}
+	session := &chatSession{Model: "ai/smollm2", Turns: []chatTurn{{Prompt: "Hi", Response: "Hello!"}}}
+	if err := session.saveNamed("debug"); err != nil {
+		t.Fatalf("saveNamed() error = %v", err)
+	}
+	loaded, err := loadNamedChatSession("debug")
+	if err != nil || loaded == nil || len(loaded.Turns) != 1 {
+		t.Fatalf("loadNamedChatSession() = %v, %v, want the saved session", loaded, err)
+	}
+
+	sessions, err := listNamedChatSessions()
+	if err != nil || len(sessions) != 1 || sessions[0].Name != "debug" || sessions[0].Turns != 1 {
+		t.Errorf("listNamedChatSessions() = %v, %v, want the saved session", sessions, err)
+	}
+
+	if err := removeNamedChatSession("debug"); err != nil {
+		t.Fatalf("removeNamedChatSession() error = %v", err)
+	}
+	if err := removeNamedChatSession("debug"); err == nil {
+		t.Error("removeNamedChatSession() succeeded for a removed session")
+	}
+
+	for _, name := range []string{"", "../escape", "a/b", ".hidden"} {
+		if _, err := namedSessionPath(name); err == nil {
+			t.Errorf("namedSessionPath(%q) succeeded, want an error", name)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package completion
 
 import (
+	"context"
+	"slices"
 	"strings"
 
 	"github.com/docker/model-cli/desktop"
@@ -34,6 +36,63 @@ func ModelNames(desktopClient func() *desktop.Client, limit int) cobra.Completio
 	}
 }
 
+// LoadedModelNames offers completion for the models currently loaded by the
+// model runner.
+func LoadedModelNames(desktopClient func() *desktop.Client, limit int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// HACK: Invoke rootCmd's PersistentPreRunE, which is needed for context
+		// detection and client initialization. This function isn't invoked
+		// automatically on autocompletion paths.
+		cmd.Parent().PersistentPreRunE(cmd, args)
+
+		if limit > 0 && len(args) >= limit {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		statuses, err := desktopClient().PS()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var names []string
+		for _, status := range statuses {
+			// A model can be loaded in several modes or by several backends.
+			if !slices.Contains(names, status.ModelName) {
+				names = append(names, status.ModelName)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// ModelNamesAndCatalog offers completion for models present within the local
+// store and for those of a remote catalog, which is only queried once the
+// local store has been listed.
+func ModelNamesAndCatalog(desktopClient func() *desktop.Client, catalog func(ctx context.Context) []string, limit int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// HACK: Invoke rootCmd's PersistentPreRunE, which is needed for context
+		// detection and client initialization. This function isn't invoked
+		// automatically on autocompletion paths.
+		cmd.Parent().PersistentPreRunE(cmd, args)
+
+		if limit > 0 && len(args) >= limit {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var names []string
+		// The local store is optional here, since models can be pulled
+		// without it.
+		if models, err := desktopClient().List(); err == nil {
+			for _, m := range models {
+				names = append(names, m.Tags...)
+			}
+		}
+		for _, name := range catalog(cmd.Context()) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
 // ModelNamesAndTags offers completion that matches the base model name along with its tags.
 // If the model has multiple tags, match both the base model name and each tag.
 func ModelNamesAndTags(desktopClient func() *desktop.Client, limit int) cobra.CompletionFunc {
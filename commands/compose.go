@@ -1,18 +1,19 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/docker/model-cli/pkg/types"
 	"github.com/spf13/pflag"
 
 	"github.com/docker/model-cli/desktop"
 	"github.com/docker/model-runner/pkg/inference/backends/llamacpp"
-	dmrm "github.com/docker/model-runner/pkg/inference/models"
 	"github.com/docker/model-runner/pkg/inference/scheduling"
 	"github.com/spf13/cobra"
 )
@@ -36,9 +37,11 @@ func newUpCommand() *cobra.Command {
 	var ctxSize int64
 	var rawRuntimeFlags string
 	var backend string
+	var quiet bool
 	c := &cobra.Command{
 		Use: "up",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			composeQuiet = quiet
 			if len(models) == 0 {
 				err := errors.New("options.model is required")
 				_ = sendError(err.Error())
@@ -57,7 +60,7 @@ func newUpCommand() *cobra.Command {
 				return errors.New("unable to determine standalone runner endpoint")
 			}
 
-			if err := downloadModelsOnlyIfNotFound(desktopClient, models); err != nil {
+			if err := downloadModelsOnlyIfNotFound(cmd.Context(), desktopClient, models); err != nil {
 				return err
 			}
 
@@ -100,6 +103,7 @@ func newUpCommand() *cobra.Command {
 	c.Flags().Int64Var(&ctxSize, "context-size", -1, "context size for the model")
 	c.Flags().StringVar(&rawRuntimeFlags, "runtime-flags", "", "raw runtime flags to pass to the inference engine")
 	c.Flags().StringVar(&backend, "backend", llamacpp.Name, "inference backend to use")
+	c.Flags().BoolVar(&quiet, "quiet", false, "only report errors, without progress and info messages")
 	_ = c.MarkFlagRequired("model")
 	return c
 }
@@ -137,7 +141,7 @@ func newMetadataCommand(upCmd, downCmd *cobra.Command) *cobra.Command {
 	return c
 }
 
-func downloadModelsOnlyIfNotFound(desktopClient *desktop.Client, models []string) error {
+func downloadModelsOnlyIfNotFound(ctx context.Context, desktopClient *desktop.Client, models []string) error {
 	modelsDownloaded, err := desktopClient.List()
 	if err != nil {
 		_ = sendErrorf("Failed to get models list: %v", err)
@@ -145,7 +149,7 @@ func downloadModelsOnlyIfNotFound(desktopClient *desktop.Client, models []string
 	}
 	for _, model := range models {
 		// Download the model if not already present in the local model store
-		if !slices.ContainsFunc(modelsDownloaded, func(m dmrm.Model) bool {
+		if !slices.ContainsFunc(modelsDownloaded, func(m desktop.Model) bool {
 			if model == m.ID {
 				return true
 			}
@@ -156,13 +160,18 @@ func downloadModelsOnlyIfNotFound(desktopClient *desktop.Client, models []string
 			}
 			return false
 		}) {
-			_, _, err = desktopClient.Pull(model, false, func(s string) {
+			if err := ensureOnline("pull " + model); err != nil {
+				_ = sendErrorf("Failed to pull model: %v", err)
+				return err
+			}
+			_, _, err = desktopClient.Pull(ctx, model, false, throttledProgress(composeProgressInterval, func(s string) {
 				_ = sendInfo(s)
-			})
+			}))
 			if err != nil {
 				_ = sendErrorf("Failed to pull model: %v", err)
 				return fmt.Errorf("Failed to pull model: %v\n", err)
 			}
+			_ = sendInfo("Successfully pulled " + model)
 		}
 
 	}
@@ -170,6 +179,22 @@ func downloadModelsOnlyIfNotFound(desktopClient *desktop.Client, models []string
 	return nil
 }
 
+// composeProgressInterval is the minimum interval between the pull progress
+// messages sent to Compose, which logs each of them.
+const composeProgressInterval = time.Second
+
+// throttledProgress returns a progress function that forwards messages to
+// progress at most once per interval and drops the others.
+func throttledProgress(interval time.Duration, progress func(string)) func(string) {
+	var last time.Time
+	return func(message string) {
+		if now := time.Now(); now.Sub(last) >= interval {
+			last = now
+			progress(message)
+		}
+	}
+}
+
 type jsonMessage struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
@@ -203,7 +228,14 @@ func sendError(message string) error {
 	return err
 }
 
+// composeQuiet drops the info messages sent to Compose, which logs each of
+// them. Errors and environment variables are still sent.
+var composeQuiet bool
+
 func sendInfo(s string) error {
+	if composeQuiet {
+		return nil
+	}
 	marshal, err := json.Marshal(jsonMessage{
 		Type:    "info",
 		Message: s,
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "config",
+		Short: "Manage Docker Model CLI settings",
+		Long: "Manage Docker Model CLI settings.\n\n" +
+			"Settings are used as defaults when the corresponding flag or environment variable is not specified.",
+	}
+	c.AddCommand(
+		newConfigSetCmd(),
+		newConfigGetCmd(),
+		newConfigUnsetCmd(),
+		newConfigListCmd(),
+		newConfigUnsetKeyCmd(),
+	)
+	return c
+}
+
+func newConfigSetCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set a setting",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf(
+					"'docker model config set' requires 2 arguments.\n\n" +
+						"Usage:  docker model config set KEY VALUE\n\n" +
+						"See 'docker model config set --help' for more information",
+				)
+			}
+			return config.ValidateKey(args[0])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[0] == config.KeyDefaultBackend {
+				if err := validateBackend(args[1]); err != nil {
+					return err
+				}
+			}
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if err := cfg.Set(args[0], args[1]); err != nil {
+				return err
+			}
+			return cfg.Save()
+		},
+		ValidArgsFunction: configKeys,
+	}
+	return c
+}
+
+func newConfigGetCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "get KEY",
+		Short: "Display a setting",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(
+					"'docker model config get' requires 1 argument.\n\n" +
+						"Usage:  docker model config get KEY\n\n" +
+						"See 'docker model config get --help' for more information",
+				)
+			}
+			return config.ValidateKey(args[0])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			cmd.Println(value)
+			return nil
+		},
+		ValidArgsFunction: configKeys,
+	}
+	return c
+}
+
+func newConfigUnsetCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Remove a setting",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(
+					"'docker model config unset' requires 1 argument.\n\n" +
+						"Usage:  docker model config unset KEY\n\n" +
+						"See 'docker model config unset --help' for more information",
+				)
+			}
+			return config.ValidateKey(args[0])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			if err := cfg.Unset(args[0]); err != nil {
+				return err
+			}
+			return cfg.Save()
+		},
+		ValidArgsFunction: configKeys,
+	}
+	return c
+}
+
+func newConfigListCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List all settings",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			for _, key := range config.Keys() {
+				value, _ := cfg.Get(key)
+				cmd.Printf("%s=%s\n", key, value)
+			}
+			return nil
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	return c
+}
+
+func newConfigUnsetKeyCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "unset-key BACKEND",
+		Short: "Remove the API key saved for a backend with run --save-key",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(
+					"'docker model config unset-key' requires 1 argument.\n\n" +
+						"Usage:  docker model config unset-key BACKEND\n\n" +
+						"See 'docker model config unset-key --help' for more information",
+				)
+			}
+			return validateBackend(args[0])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := removeAPIKey(args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no API key is saved for %s", args[0])
+			}
+			return nil
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	return c
+}
+
+// configKeys completes the first argument with the supported setting keys.
+func configKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.Keys(), cobra.ShellCompDirectiveNoFileComp
+}
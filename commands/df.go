@@ -2,32 +2,153 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/docker/docker/client"
 	"github.com/docker/go-units"
 	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/commands/formatter"
 	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/standalone"
+	"github.com/docker/model-cli/pkg/types"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 func newDFCmd() *cobra.Command {
+	var warnThreshold string
 	c := &cobra.Command{
 		Use:   "df",
 		Short: "Show Docker Model Runner disk usage",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var threshold float64
+			if warnThreshold != "" {
+				var err error
+				if threshold, err = parsePercentage(warnThreshold); err != nil {
+					return fmt.Errorf("invalid --warn-threshold: %w", err)
+				}
+			}
 			df, err := desktopClient.DF()
 			if err != nil {
 				err = handleClientError(err, "Failed to list running models")
 				return handleNotRunningError(err)
 			}
-			cmd.Print(diskUsageTable(df))
+			if warnThreshold == "" {
+				if jsonOutput {
+					return printDiskUsageJSON(cmd, diskUsageJSON{DiskUsage: df})
+				}
+				cmd.Print(diskUsageTable(df))
+				return nil
+			}
+
+			capacity, err := modelStorageCapacity(cmd.Context())
+			if err != nil {
+				return err
+			}
+			percent := 100 * float64(df.ModelsDiskUsage) / float64(capacity.Total)
+			if jsonOutput {
+				if err := printDiskUsageJSON(cmd, diskUsageJSON{
+					DiskUsage:    df,
+					Capacity:     &capacity.Total,
+					Available:    &capacity.Available,
+					UsagePercent: &percent,
+				}); err != nil {
+					return err
+				}
+			} else {
+				cmd.Print(diskUsageTable(df))
+				cmd.Printf("\nModels use %s of %s (%.1f%%), %s available\n",
+					formatSize(df.ModelsDiskUsage), formatSize(int64(capacity.Total)), percent,
+					formatSize(int64(capacity.Available)))
+			}
+			if percent > threshold {
+				return fmt.Errorf("models disk usage %.1f%% exceeds the warning threshold of %g%%", percent, threshold)
+			}
 			return nil
 		},
 		ValidArgsFunction: completion.NoComplete,
 	}
+	c.Flags().StringVar(&warnThreshold, "warn-threshold", "", "Exit with an error if models use more than this percentage of the storage capacity (e.g. 80%)")
 	return c
 }
 
+// diskUsageJSON is the JSON output of df. The capacity fields are only set
+// when --warn-threshold is specified.
+type diskUsageJSON struct {
+	desktop.DiskUsage
+	Capacity     *uint64  `json:"capacity,omitempty"`
+	Available    *uint64  `json:"available,omitempty"`
+	UsagePercent *float64 `json:"usage_percent,omitempty"`
+}
+
+func printDiskUsageJSON(cmd *cobra.Command, df diskUsageJSON) error {
+	output, err := formatter.ToStandardJSON(df)
+	if err != nil {
+		return err
+	}
+	cmd.Print(output)
+	return nil
+}
+
+// parsePercentage parses a percentage such as "80%" or "80".
+func parsePercentage(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a percentage (got %q)", value)
+	}
+	if percent <= 0 || percent > 100 {
+		return 0, fmt.Errorf("percentage must be greater than 0 and at most 100 (got %q)", value)
+	}
+	return percent, nil
+}
+
+// modelStorageCapacity determines the capacity of the filesystem backing model
+// storage. This is only possible for standalone model runners.
+func modelStorageCapacity(ctx context.Context) (standalone.StorageCapacity, error) {
+	dockerClient, containerID, err := standaloneController(ctx, "storage capacity can only be determined")
+	if err != nil {
+		return standalone.StorageCapacity{}, err
+	}
+	capacity, err := standalone.ModelStorageCapacity(ctx, dockerClient, containerID)
+	if err != nil {
+		return standalone.StorageCapacity{}, fmt.Errorf("unable to determine storage capacity: %w", err)
+	}
+	if capacity.Total == 0 {
+		return standalone.StorageCapacity{}, errors.New("unable to determine storage capacity: filesystem reports zero size")
+	}
+	return capacity, nil
+}
+
+// standaloneController returns a Docker client and the ID of the controller
+// container of the standalone model runner, for operations on its model
+// storage. unsupported describes the operation for the error returned with
+// other model runners, e.g. "storage capacity can only be determined".
+func standaloneController(ctx context.Context, unsupported string) (client.APIClient, string, error) {
+	engineKind := modelRunner.EngineKind()
+	if engineKind != types.ModelRunnerEngineKindMoby && engineKind != types.ModelRunnerEngineKindCloud {
+		return nil, "", fmt.Errorf("%s for standalone model runners", unsupported)
+	}
+	dockerClient, err := desktop.DockerClientForContext(dockerCLI, dockerCLI.CurrentContext())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	containerID, _, _, err := standalone.FindControllerContainer(ctx, dockerClient, runnerName)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to identify standalone model runner: %w", err)
+	} else if containerID == "" {
+		return nil, "", errors.New("no standalone model runner is running")
+	}
+	return dockerClient, containerID, nil
+}
+
+func formatSize(size int64) string {
+	return units.CustomSize("%.2f%s", float64(size), 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"})
+}
+
 func diskUsageTable(df desktop.DiskUsage) string {
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
@@ -46,9 +167,9 @@ func diskUsageTable(df desktop.DiskUsage) string {
 	})
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 
-	table.Append([]string{"Models", units.CustomSize("%.2f%s", float64(df.ModelsDiskUsage), 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"})})
+	table.Append([]string{"Models", formatSize(df.ModelsDiskUsage)})
 	if df.DefaultBackendDiskUsage != 0 {
-		table.Append([]string{"Inference engine", units.CustomSize("%.2f%s", float64(df.DefaultBackendDiskUsage), 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"})})
+		table.Append([]string{"Inference engine", formatSize(df.DefaultBackendDiskUsage)})
 	}
 
 	table.Render()
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/docker/cli/cli/debug"
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/commands/formatter"
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/standalone"
+	"github.com/docker/model-cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// Model runners accepted by --prefer.
+const (
+	preferDesktop    = "desktop"
+	preferStandalone = "standalone"
+)
+
+// preferredRunner is set by the global --prefer flag.
+var preferredRunner string
+
+// checkRunnerPreference validates --prefer.
+func checkRunnerPreference() error {
+	switch preferredRunner {
+	case "", preferStandalone:
+		return nil
+	case preferDesktop:
+		if treatDesktopAsMoby {
+			return errors.New("--prefer desktop cannot be used with --treat-desktop-as-moby")
+		}
+		return nil
+	default:
+		return fmt.Errorf("--prefer must be %s or %s (got %q)", preferDesktop, preferStandalone, preferredRunner)
+	}
+}
+
+// detectModelRunner detects the model runner context according to --prefer.
+// With Docker Desktop, the built-in model runner is used unless a standalone
+// one is preferred and installed. If the preferred model runner isn't
+// available, the detected one is used instead, with a notice. In debug mode,
+// it also reports which model runner was chosen and why.
+func detectModelRunner(cmd *cobra.Command) (*desktop.ModelRunnerContext, error) {
+	opts := desktop.DetectOptions{
+		TreatDesktopAsMoby: standalone.TreatDesktopAsMoby() && preferredRunner != preferDesktop,
+		// install-runner is how a preferred standalone runner gets installed.
+		PreferStandalone: preferredRunner == preferStandalone &&
+			(cmd.Name() == "install-runner" || standaloneRunnerInstalled(cmd)),
+	}
+	runner, err := desktop.DetectContext(cmd.Context(), dockerCLI, opts)
+	if err != nil {
+		return nil, err
+	}
+	kind := runner.EngineKind()
+	reason := "detected " + kind.String()
+	switch {
+	case opts.TreatDesktopAsMoby:
+		reason = standalone.TreatDesktopAsMobyEnv + " is set"
+	case preferredRunner == preferDesktop && kind != types.ModelRunnerEngineKindDesktop:
+		cmd.PrintErrf("Docker Desktop's model runner isn't available in this context, using the %s\n", runnerDescription(runner))
+	case preferredRunner == preferStandalone && kind == types.ModelRunnerEngineKindDesktop:
+		cmd.PrintErrln("No standalone model runner is installed, using the Docker Desktop model runner " +
+			"(run 'docker model --prefer standalone install-runner' to install one)")
+	case preferredRunner == preferStandalone && kind == types.ModelRunnerEngineKindMobyManual:
+		cmd.PrintErrf("MODEL_RUNNER_HOST or default-host is set, using the %s\n", runnerDescription(runner))
+	case preferredRunner != "":
+		reason = "--prefer " + preferredRunner
+	}
+	if debug.IsEnabled() {
+		cmd.PrintErrf("Using the %s (%s)\n", runnerDescription(runner), reason)
+	}
+	return runner, nil
+}
+
+// standaloneRunnerInstalled reports whether a standalone model runner
+// container is running on the engine of the current context.
+func standaloneRunnerInstalled(cmd *cobra.Command) bool {
+	ctrID, _, _, err := standalone.FindControllerContainer(cmd.Context(), dockerCLI.Client(), runnerName)
+	return err == nil && ctrID != ""
+}
+
+// runnerDescription describes the model runner of a context.
+func runnerDescription(runner *desktop.ModelRunnerContext) string {
+	switch runner.EngineKind() {
+	case types.ModelRunnerEngineKindDesktop:
+		return "Docker Desktop model runner"
+	case types.ModelRunnerEngineKindMobyManual:
+		return "model runner at " + runner.URL("")
+	default:
+		return "standalone model runner"
+	}
+}
+
+// engineInfo describes the detected model runner context.
+type engineInfo struct {
+	Kind     string `json:"kind"`
+	Endpoint string `json:"endpoint"`
+	// Standalone is true if the CLI manages a standalone model runner
+	// container for this kind of engine.
+	Standalone bool `json:"standalone"`
+	// Container is the standalone model runner container, if one is present.
+	Container *engineContainer `json:"container,omitempty"`
+}
+
+type engineContainer struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	HostPort uint16 `json:"hostPort,omitempty"`
+}
+
+func newEngineCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "engine",
+		Short: "Show the detected Docker engine kind and Docker Model Runner endpoint",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind := modelRunner.EngineKind()
+			info := engineInfo{
+				Kind:     kind.String(),
+				Endpoint: modelRunner.URL(""),
+				Standalone: kind == types.ModelRunnerEngineKindMoby ||
+					kind == types.ModelRunnerEngineKindCloud,
+			}
+			if info.Standalone {
+				dockerClient, err := desktop.DockerClientForContext(dockerCLI, dockerCLI.CurrentContext())
+				if err != nil {
+					return fmt.Errorf("failed to create Docker client: %w", err)
+				}
+				ctrID, ctrName, ctr, err := standalone.FindControllerContainer(cmd.Context(), dockerClient, runnerName)
+				if err != nil {
+					return fmt.Errorf("unable to identify Model Runner container: %w", err)
+				}
+				if ctrID != "" {
+					info.Container = &engineContainer{
+						ID:       ctrID[:12],
+						Name:     ctrName,
+						HostPort: inspectStandaloneRunner(ctr).hostPort,
+					}
+				}
+			}
+			if jsonOutput {
+				output, err := formatter.ToStandardJSON(info)
+				if err != nil {
+					return err
+				}
+				cmd.Print(output)
+				return nil
+			}
+			cmd.Print(engineText(info))
+			return nil
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	return c
+}
+
+func engineText(info engineInfo) string {
+	text := fmt.Sprintf("Engine:    %s\nEndpoint:  %s\n", info.Kind, info.Endpoint)
+	switch {
+	case !info.Standalone:
+		text += "Container: not applicable (the runner isn't managed by the CLI)\n"
+	case info.Container == nil && os.Getenv("MODEL_RUNNER_NO_AUTO_INSTALL") != "":
+		text += "Container: none (automatic installation is disabled)\n"
+	case info.Container == nil:
+		text += "Container: none (it will be installed on first use)\n"
+	case info.Container.Name != "":
+		text += fmt.Sprintf("Container: %s (%s)\n", info.Container.Name, info.Container.ID)
+	default:
+		text += fmt.Sprintf("Container: %s\n", info.Container.ID)
+	}
+	return text
+}
@@ -0,0 +1,60 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the functions available to --format templates, mirroring
+// those provided by the Docker CLI.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		output, err := ToJSON(v, "", "")
+		return strings.TrimSpace(output), err
+	},
+	"join":     strings.Join,
+	"split":    strings.Split,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"truncate": truncate,
+}
+
+// truncate shortens s to at most length runes.
+func truncate(s string, length int) string {
+	if runes := []rune(s); len(runes) > length {
+		return string(runes[:length])
+	}
+	return s
+}
+
+// Template is a parsed --format template.
+type Template struct {
+	tmpl *template.Template
+}
+
+// ParseTemplate parses a --format template.
+func ParseTemplate(format string) (*Template, error) {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// NoTrunc makes the truncate function return its input unchanged, for
+// --no-trunc.
+func (t *Template) NoTrunc() {
+	t.tmpl.Funcs(template.FuncMap{"truncate": func(s string, _ int) string { return s }})
+}
+
+// Execute renders the template for a single item, followed by a newline.
+func (t *Template) Execute(item interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, item); err != nil {
+		return "", fmt.Errorf("error executing format template: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.String(), nil
+}
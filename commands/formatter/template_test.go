@@ -0,0 +1,51 @@
+package formatter
+
+import "testing"
+
+func TestTemplate(t *testing.T) {
+	item := struct {
+		ID     string
+		Name   string
+		Config map[string]string
+	}{
+		ID:     "sha256:0123456789abcdef",
+		Name:   "SmolLM2",
+		Config: map[string]string{"format": "gguf"},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{format: "{{json .Config}}", expected: `{"format":"gguf"}` + "\n"},
+		{format: "{{truncate .ID 12}}", expected: "sha256:01234\n"},
+		{format: "{{lower .Name}} {{upper .Name}}", expected: "smollm2 SMOLLM2\n"},
+		{format: `{{join (split .ID ":") "/"}}`, expected: "sha256/0123456789abcdef\n"},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.format)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q) error = %v", tt.format, err)
+		}
+		output, err := tmpl.Execute(item)
+		if err != nil {
+			t.Fatalf("Execute(%q) error = %v", tt.format, err)
+		}
+		if output != tt.expected {
+			t.Errorf("Execute(%q) = %q, want %q", tt.format, output, tt.expected)
+		}
+	}
+
+	tmpl, err := ParseTemplate("{{truncate .ID 12}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.NoTrunc()
+	if output, err := tmpl.Execute(item); err != nil || output != item.ID+"\n" {
+		t.Errorf("Execute() with NoTrunc = %q, %v, want %q", output, err, item.ID+"\n")
+	}
+
+	if _, err := ParseTemplate("{{.ID"); err == nil {
+		t.Errorf("ParseTemplate should reject an invalid template")
+	}
+}
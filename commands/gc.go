@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
+	"github.com/spf13/cobra"
+)
+
+func newGCCmd() *cobra.Command {
+	var targetSize string
+	var dryRun bool
+
+	c := &cobra.Command{
+		Use:   "gc --target-size SIZE",
+		Short: "Remove least recently used models until disk usage is below a target size",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := units.FromHumanSize(targetSize)
+			if err != nil {
+				return fmt.Errorf("invalid --target-size: %w", err)
+			}
+			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
+				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
+			}
+			return collectGarbage(cmd, desktopClient, target, dryRun)
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+
+	c.Flags().StringVar(&targetSize, "target-size", "", "Disk usage to reduce models to (e.g. 20GB)")
+	c.Flags().BoolVar(&dryRun, "dry-run", false, "Show which models would be removed without removing them")
+	_ = c.MarkFlagRequired("target-size")
+	return c
+}
+
+// collectGarbage removes models that aren't loaded, least recently used first,
+// until the models disk usage is at most target bytes.
+func collectGarbage(cmd *cobra.Command, desktopClient *desktop.Client, target int64, dryRun bool) error {
+	df, err := desktopClient.DF()
+	if err != nil {
+		return handleNotRunningError(handleClientError(err, "Failed to get disk usage"))
+	}
+	usage := df.ModelsDiskUsage
+	if usage <= target {
+		cmd.Printf("Models disk usage %s is already within the target of %s\n", formatSize(usage), formatSize(target))
+		return nil
+	}
+
+	models, err := desktopClient.List()
+	if err != nil {
+		return handleNotRunningError(handleClientError(err, "Failed to list models"))
+	}
+	ps, err := desktopClient.PS()
+	if err != nil {
+		return handleNotRunningError(handleClientError(err, "Failed to list running models"))
+	}
+	candidates := gcCandidates(models, ps)
+
+	for _, m := range candidates {
+		if usage <= target {
+			break
+		}
+		name := m.ID
+		if len(m.Tags) > 0 {
+			name = m.Tags[0]
+		}
+		size, _ := units.RAMInBytes(m.Config.Size)
+		if dryRun {
+			cmd.Printf("Would remove %s (%s)\n", name, formatSize(size))
+			usage -= size
+			continue
+		}
+		if _, err := desktopClient.Remove([]string{m.ID}, true); err != nil {
+			return handleNotRunningError(handleClientError(err, "Failed to remove model "+name))
+		}
+		cmd.Printf("Removed %s (%s)\n", name, formatSize(size))
+		// Layers may be shared between models, so ask the runner rather than
+		// relying on the model's size.
+		if df, err = desktopClient.DF(); err != nil {
+			return handleNotRunningError(handleClientError(err, "Failed to get disk usage"))
+		}
+		usage = df.ModelsDiskUsage
+	}
+
+	if dryRun {
+		cmd.Printf("Estimated models disk usage: %s (target %s)\n", formatSize(max(usage, 0)), formatSize(target))
+	} else {
+		cmd.Printf("Models disk usage: %s (target %s)\n", formatSize(usage), formatSize(target))
+	}
+	if usage > target {
+		return fmt.Errorf("unable to reach the target size without removing loaded models")
+	}
+	return nil
+}
+
+// gcCandidates returns the models that may be removed, least recently used
+// first. Loaded models are never candidates. Since only loaded models report a
+// last-used time, the remaining models are ordered by creation time.
+func gcCandidates(models []desktop.Model, ps []desktop.BackendStatus) []desktop.Model {
+	var candidates []desktop.Model
+	for _, m := range models {
+		if !modelLoaded(m, ps) {
+			candidates = append(candidates, m)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b desktop.Model) int {
+		if a.Created != b.Created {
+			if a.Created < b.Created {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return candidates
+}
@@ -1,17 +1,36 @@
 package commands
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/docker/docker/client"
 	"github.com/docker/model-cli/commands/completion"
 	"github.com/docker/model-cli/commands/formatter"
 	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/standalone"
+	"github.com/docker/model-cli/pkg/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 )
 
 func newInspectCmd() *cobra.Command {
 	var openai bool
 	var remote bool
+	var verify bool
+	var probe bool
+	var remoteFallback bool
+	var format string
+	var raw bool
+	var oci bool
+	var platformSpec string
 	c := &cobra.Command{
 		Use:   "inspect MODEL",
 		Short: "Display detailed information on one model",
@@ -32,7 +51,71 @@ func newInspectCmd() *cobra.Command {
 			if openai && remote {
 				return fmt.Errorf("--remote flag cannot be used with --openai flag")
 			}
-			inspectedModel, err := inspectModel(args, openai, remote, desktopClient)
+			if remote {
+				if err := ensureOnline("inspect remote models"); err != nil {
+					return err
+				}
+			}
+			if oci && (openai || format != "" || raw) {
+				return fmt.Errorf("--oci flag cannot be used with --openai, --format, or --raw flags")
+			}
+			var platform *v1.Platform
+			if platformSpec != "" {
+				if !oci {
+					return fmt.Errorf("--platform flag can only be used with --oci flag")
+				}
+				var err error
+				if platform, err = v1.ParsePlatform(platformSpec); err != nil {
+					return fmt.Errorf("invalid --platform: %w", err)
+				}
+			}
+			if probe {
+				if openai || remote || raw || oci || verify || remoteFallback || (format != "" && format != "json") {
+					return fmt.Errorf("--probe flag cannot be used with --openai, --remote, --remote-fallback, --raw, --oci, or --verify flags, or --format other than json")
+				}
+				return probeModel(cmd, desktopClient, args[0], format == "json")
+			}
+			if verify {
+				if openai || remote || format != "" || raw || oci {
+					return fmt.Errorf("--verify flag cannot be used with --openai, --remote, --format, --raw, or --oci flags")
+				}
+				return verifyModel(cmd, desktopClient, args[0])
+			}
+			if raw && format != "" {
+				return fmt.Errorf("--raw flag cannot be used with --format flag")
+			}
+			if remoteFallback && (openai || remote) {
+				return fmt.Errorf("--remote-fallback flag cannot be used with --openai or --remote flags")
+			}
+			// --format json prints the model on a single line, unlike the
+			// default output.
+			compact := format == "json"
+			var tmpl *formatter.Template
+			if format != "" && !compact {
+				var err error
+				if tmpl, err = formatter.ParseTemplate(format); err != nil {
+					return err
+				}
+			}
+			inspect := func(openai, remote bool) (string, error) {
+				if oci {
+					return inspectManifest(cmd, args[0], remote, platform, desktopClient)
+				}
+				if raw {
+					return inspectModelRaw(args[0], openai, remote, desktopClient)
+				}
+				return inspectModel(cmd, args, openai, remote, desktopClient, tmpl, compact)
+			}
+			var inspectedModel string
+			var err error
+			if remoteFallback {
+				inspectedModel, err = withRemoteFallback(cmd, args[0], "inspect remote models", "showing information from the registry",
+					func(remote bool) (string, error) {
+						return inspect(false, remote)
+					})
+			} else {
+				inspectedModel, err = inspect(openai, remote)
+			}
 			if err != nil {
 				return err
 			}
@@ -43,23 +126,322 @@ func newInspectCmd() *cobra.Command {
 	}
 	c.Flags().BoolVar(&openai, "openai", false, "List model in an OpenAI format")
 	c.Flags().BoolVarP(&remote, "remote", "r", false, "Show info for remote models")
+	c.Flags().BoolVar(&remoteFallback, "remote-fallback", false, "Show info from the registry if the model isn't available locally")
+	c.Flags().StringVar(&format, "format", "", "Format the output as single-line json or using the given Go template (e.g. '{{.Config.Architecture}}')")
+	c.Flags().BoolVar(&raw, "raw", false, "Print the model runner's response as is, including fields the CLI doesn't know about")
+	c.Flags().BoolVar(&oci, "oci", false, "Show the model's OCI manifest as stored (standalone model runners only), or as in the registry with --remote")
+	c.Flags().StringVar(&platformSpec, "platform", "", "Show the manifest for the given platform (os/arch[/variant]) of a multi-platform model (with --oci)")
+	c.Flags().BoolVar(&probe, "probe", false, "Send a short test request to the model and report whether it responds, and how fast")
+	c.Flags().BoolVar(&verify, "verify", false, "Verify the digests of the model's stored layers against its manifest (standalone model runners only)")
 	return c
 }
 
-func inspectModel(args []string, openai bool, remote bool, desktopClient *desktop.Client) (string, error) {
+// withRemoteFallback calls get for the locally stored model and, if it isn't
+// available locally, calls it again for the model in the registry. The
+// message printed on falling back ends with action, and operation describes
+// what requires the registry if offline.
+func withRemoteFallback[T any](cmd *cobra.Command, model, operation, action string, get func(remote bool) (T, error)) (T, error) {
+	result, err := get(false)
+	if err == nil || !errors.Is(err, desktop.ErrNotFound) {
+		return result, err
+	}
+	if err := ensureOnline(operation); err != nil {
+		var zero T
+		return zero, err
+	}
+	cmd.PrintErrf("Model %s not found locally, %s\n", model, action)
+	return get(true)
+}
+
+func inspectModel(cmd *cobra.Command, args []string, openai bool, remote bool, desktopClient *desktop.Client, tmpl *formatter.Template, compact bool) (string, error) {
 	modelName := args[0]
+	var model interface{}
+	var err error
 	if openai {
-		model, err := desktopClient.InspectOpenAI(modelName)
-		if err != nil {
-			err = handleClientError(err, "Failed to get model "+modelName)
-			return "", handleNotRunningError(err)
-		}
-		return formatter.ToStandardJSON(model)
+		model, err = desktopClient.InspectOpenAI(modelName)
+	} else {
+		model, err = desktopClient.Inspect(modelName, remote)
 	}
-	model, err := desktopClient.Inspect(modelName, remote)
 	if err != nil {
 		err = handleClientError(err, "Failed to get model "+modelName)
 		return "", handleNotRunningError(err)
 	}
+	if tmpl != nil {
+		return tmpl.Execute(model)
+	}
+	if m, ok := model.(desktop.Model); ok {
+		if unknown := m.UnknownFields(); len(unknown) > 0 {
+			cmd.PrintErrf("Note: fields unknown to this version of the CLI are shown as reported by the model runner: %s\n", strings.Join(unknown, ", "))
+		}
+	}
+	if compact {
+		return formatter.ToJSON(model, "", "")
+	}
 	return formatter.ToStandardJSON(model)
 }
+
+// inspectModelRaw returns the model runner's inspect response, pretty-printed
+// but otherwise unchanged.
+func inspectModelRaw(modelName string, openai, remote bool, desktopClient *desktop.Client) (string, error) {
+	var rawResponse []byte
+	var err error
+	if openai {
+		rawResponse, err = desktopClient.InspectOpenAIRaw(modelName)
+	} else {
+		rawResponse, err = desktopClient.InspectRaw(modelName, remote)
+	}
+	if err != nil {
+		err = handleClientError(err, "Failed to get model "+modelName)
+		return "", handleNotRunningError(err)
+	}
+	if !json.Valid(rawResponse) {
+		return "", fmt.Errorf("the model runner returned an invalid response: %s", rawResponse)
+	}
+	return formatter.ToStandardJSON(json.RawMessage(rawResponse))
+}
+
+// inspectManifest returns the OCI manifest of a model, pretty-printed. With
+// fromRegistry, the manifest is fetched from the registry directly rather
+// than from the model runner. If the model is an image index, platform
+// selects the manifest to return; otherwise the index is returned and its
+// platforms are listed on stderr.
+func inspectManifest(cmd *cobra.Command, modelName string, fromRegistry bool, platform *v1.Platform, desktopClient *desktop.Client) (string, error) {
+	var ref name.Reference
+	var manifest []byte
+	var err error
+	if fromRegistry {
+		if ref, err = name.ParseReference(modelName); err != nil {
+			return "", err
+		}
+		manifest, err = registryManifest(cmd.Context(), ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to get manifest of %s from the registry: %w", modelName, err)
+		}
+	} else {
+		dockerClient, containerID, err := standaloneController(cmd.Context(), "stored manifests can only be shown")
+		if err != nil {
+			return "", fmt.Errorf("%w; use --remote to get the manifest from the registry", err)
+		}
+		manifest, err = storedManifest(cmd.Context(), desktopClient, dockerClient, containerID, modelName)
+		if err != nil {
+			err = handleClientError(err, "Failed to get manifest of model "+modelName)
+			return "", handleNotRunningError(err)
+		}
+	}
+	if !json.Valid(manifest) {
+		return "", fmt.Errorf("invalid manifest: %s", manifest)
+	}
+
+	manifests := indexManifests(manifest)
+	if platform != nil {
+		if manifests == nil {
+			return "", fmt.Errorf("%s is not a multi-platform image index", modelName)
+		}
+		selected, err := selectPlatform(manifests, *platform)
+		if err != nil {
+			return "", err
+		}
+		if !fromRegistry {
+			return "", fmt.Errorf("selecting a platform of %s requires --remote", modelName)
+		}
+		manifest, err = registryManifest(cmd.Context(), ref.Context().Digest(selected.Digest.String()))
+		if err != nil {
+			return "", fmt.Errorf("failed to get manifest of %s for %s from the registry: %w", modelName, platform, err)
+		}
+	} else if manifests != nil {
+		cmd.PrintErrf("%s is an image index for %d manifest(s):\n", modelName, len(manifests))
+		for _, m := range manifests {
+			cmd.PrintErrf("  %-16s %s\n", platformString(m.Platform), m.Digest)
+		}
+		cmd.PrintErrln("Use --platform to show the manifest for one of the platforms.")
+	}
+	return formatter.ToStandardJSON(json.RawMessage(manifest))
+}
+
+// registryManifest returns the manifest of a model in the registry as is.
+func registryManifest(ctx context.Context, ref name.Reference) ([]byte, error) {
+	keychain, err := registryKeychain()
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := remote.Get(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return nil, err
+	}
+	return descriptor.Manifest, nil
+}
+
+// indexManifests returns the manifests listed in an OCI image index, or nil
+// if manifest isn't an index.
+func indexManifests(manifest []byte) []v1.Descriptor {
+	var index v1.IndexManifest
+	if err := json.Unmarshal(manifest, &index); err != nil {
+		return nil
+	}
+	if !index.MediaType.IsIndex() && len(index.Manifests) == 0 {
+		return nil
+	}
+	return index.Manifests
+}
+
+// selectPlatform returns the first manifest of an index that satisfies the
+// platform.
+func selectPlatform(manifests []v1.Descriptor, platform v1.Platform) (v1.Descriptor, error) {
+	var available []string
+	for _, m := range manifests {
+		if m.Platform != nil && m.Platform.Satisfies(platform) {
+			return m, nil
+		}
+		available = append(available, platformString(m.Platform))
+	}
+	return v1.Descriptor{}, fmt.Errorf("no manifest for platform %s; available platforms: %s", platform, strings.Join(available, ", "))
+}
+
+func platformString(platform *v1.Platform) string {
+	if platform == nil {
+		return "unknown"
+	}
+	return platform.String()
+}
+
+// storedManifest returns the manifest of a model as is, reading it from the
+// model storage of the standalone model runner's controller container.
+func storedManifest(ctx context.Context, desktopClient *desktop.Client, dockerClient client.APIClient, containerID, model string) ([]byte, error) {
+	inspected, err := desktopClient.Inspect(model, false)
+	if err != nil {
+		return nil, err
+	}
+	return standalone.StoredManifest(ctx, dockerClient, containerID, inspected.ID)
+}
+
+// errVerifyUnsupported is returned by verifyStoredModel for model runners
+// whose model storage the CLI can't read.
+var errVerifyUnsupported = errors.New("model verification is only available for standalone model runners")
+
+// verifyStoredModel checks the digests of the stored layers of a model
+// against its manifest, reading both from the standalone model runner's model
+// storage. It returns the results for all layers.
+func verifyStoredModel(ctx context.Context, desktopClient *desktop.Client, model string) ([]standalone.LayerVerification, error) {
+	engineKind := modelRunner.EngineKind()
+	if engineKind != types.ModelRunnerEngineKindMoby && engineKind != types.ModelRunnerEngineKindCloud {
+		return nil, errVerifyUnsupported
+	}
+	dockerClient, containerID, err := standaloneController(ctx, "model verification is only available")
+	if err != nil {
+		return nil, err
+	}
+	raw, err := storedManifest(ctx, desktopClient, dockerClient, containerID, model)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := v1.ParseManifest(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest of %s: %w", model, err)
+	}
+	return standalone.VerifyStoredLayers(ctx, dockerClient, containerID, manifest)
+}
+
+// corruptLayers returns the layers that failed verification.
+func corruptLayers(layers []standalone.LayerVerification) []standalone.LayerVerification {
+	var corrupt []standalone.LayerVerification
+	for _, layer := range layers {
+		if !layer.Valid {
+			corrupt = append(corrupt, layer)
+		}
+	}
+	return corrupt
+}
+
+func verifyModel(cmd *cobra.Command, desktopClient *desktop.Client, model string) error {
+	layers, err := verifyStoredModel(cmd.Context(), desktopClient, model)
+	if err != nil {
+		if errors.Is(err, errVerifyUnsupported) {
+			return err
+		}
+		err = handleClientError(err, "Failed to verify model "+model)
+		return handleNotRunningError(err)
+	}
+	corrupt := corruptLayers(layers)
+	if len(corrupt) == 0 {
+		cmd.Printf("OK: %d layer(s) verified\n", len(layers))
+		return nil
+	}
+	for _, layer := range corrupt {
+		if layer.Error != "" {
+			cmd.Printf("CORRUPT %s: %s\n", layer.Digest, layer.Error)
+		} else {
+			cmd.Printf("CORRUPT %s\n", layer.Digest)
+		}
+	}
+	return fmt.Errorf("model %s has %d corrupt layer(s) out of %d; run 'docker model pull --repair %s' to repair it",
+		model, len(corrupt), len(layers), model)
+}
+
+// probeTimeout bounds the time a probe waits for the model, which includes
+// the time to load it.
+const probeTimeout = 2 * time.Minute
+
+// probeResult is the JSON representation of the outcome of a probe.
+type probeResult struct {
+	Model              string  `json:"model"`
+	OK                 bool    `json:"ok"`
+	TimeToFirstTokenMS float64 `json:"time_to_first_token_ms,omitempty"`
+	TotalMS            float64 `json:"total_ms"`
+	Error              string  `json:"error,omitempty"`
+}
+
+// probeModel requests a single token from a model to check that it's not
+// only stored but also runnable, and reports the latency of the response.
+// The model is loaded if it isn't already, so the latency includes loading it.
+func probeModel(cmd *cobra.Command, desktopClient *desktop.Client, model string, jsonFormat bool) error {
+	if _, err := desktopClient.Inspect(model, false); err != nil {
+		err = handleClientError(err, "Failed to get model "+model)
+		return handleNotRunningError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), probeTimeout)
+	defer cancel()
+	maxTokens := 1
+	var timing desktop.ResponseTiming
+	_, err := desktopClient.Chat(ctx, "", model, "Hi", "", desktop.ChatOptions{
+		Sampling:        desktop.SamplingOptions{MaxTokens: &maxTokens},
+		StopAfterTokens: 1,
+		Timing:          &timing,
+	}, func(desktop.ChatDelta) {})
+	if errors.Is(err, desktop.ErrTruncated) {
+		err = nil
+	}
+	if err == nil && timing.Tokens == 0 {
+		err = errors.New("the model responded without any tokens")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("no response within %s", probeTimeout)
+	}
+	if err != nil {
+		err = handleNotRunningError(handleClientError(err, "Probe failed"))
+	}
+
+	result := probeResult{Model: model, OK: err == nil, TotalMS: float64(timing.Total.Microseconds()) / 1000}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.TimeToFirstTokenMS = float64(timing.TimeToFirstToken.Microseconds()) / 1000
+	}
+	if jsonFormat {
+		output, jsonErr := formatter.ToJSON(result, "", "")
+		if jsonErr != nil {
+			return jsonErr
+		}
+		cmd.Print(output)
+		if err != nil {
+			return fmt.Errorf("model %s failed the probe", model)
+		}
+		return nil
+	}
+	if err != nil {
+		cmd.Printf("FAIL: %s\n", model)
+		return err
+	}
+	cmd.Printf("OK: %s responded, time to first token %s\n", model, timing.TimeToFirstToken.Round(time.Millisecond))
+	return nil
+}
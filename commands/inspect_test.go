@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestIndexManifests(t *testing.T) {
+	index := []byte(`{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.oci.image.index.v1+json",
+		"manifests": [
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:` + strings.Repeat("a", 64) + `", "size": 100, "platform": {"os": "linux", "architecture": "amd64"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:` + strings.Repeat("b", 64) + `", "size": 100, "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}}
+		]
+	}`)
+	manifests := indexManifests(index)
+	if len(manifests) != 2 {
+		t.Fatalf("indexManifests() returned %d manifests, want 2", len(manifests))
+	}
+
+	selected, err := selectPlatform(manifests, v1.Platform{OS: "linux", Architecture: "arm64"})
+	if err != nil {
+		t.Fatalf("selectPlatform() error = %v", err)
+	}
+	if want := "sha256:" + strings.Repeat("b", 64); selected.Digest.String() != want {
+		t.Errorf("selectPlatform() = %s, want %s", selected.Digest, want)
+	}
+
+	_, err = selectPlatform(manifests, v1.Platform{OS: "windows", Architecture: "amd64"})
+	if err == nil || !strings.Contains(err.Error(), "linux/amd64, linux/arm64/v8") {
+		t.Errorf("selectPlatform() error = %v, want the available platforms listed", err)
+	}
+
+	manifest := []byte(`{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.manifest.v1+json", "layers": []}`)
+	if manifests := indexManifests(manifest); manifests != nil {
+		t.Errorf("indexManifests() = %v for an image manifest, want nil", manifests)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/docker/model-cli/pkg/types"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
@@ -32,7 +33,7 @@ const (
 // version can take several seconds.
 func waitForStandaloneRunnerAfterInstall(ctx context.Context) error {
 	for tries := installWaitTries; tries > 0; tries-- {
-		if status := desktopClient.Status(); status.Error == nil && status.Running {
+		if runnerReady() {
 			return nil
 		}
 		select {
@@ -44,6 +45,33 @@ func waitForStandaloneRunnerAfterInstall(ctx context.Context) error {
 	return errors.New("standalone model runner took too long to initialize")
 }
 
+// parseLabels parses key=value label arguments. A label without a value is
+// set to the empty string.
+func parseLabels(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", arg)
+		}
+		labels[key] = value
+	}
+	if err := standalone.CheckExtraLabels(labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// runnerReady returns true if the model runner responds successfully to a
+// model listing request.
+func runnerReady() bool {
+	status := desktopClient.Status()
+	return status.Error == nil && status.Running
+}
+
 // standaloneRunner encodes the standalone runner configuration, if one exists.
 type standaloneRunner struct {
 	// hostPort is the port that the runner is listening to on the host.
@@ -72,6 +100,38 @@ func inspectStandaloneRunner(container container.Summary) *standaloneRunner {
 	return result
 }
 
+// runnerName is the name of the standalone model runner container targeted by
+// commands, as set by the --runner flag. If empty, the first model runner
+// container found is used.
+var runnerName string
+
+// targetRunner points the model runner context at the standalone model runner
+// selected via --runner, if any.
+func targetRunner(cmd *cobra.Command) error {
+	// These commands manage containers by name themselves.
+	if runnerName == "" || cmd.Name() == "install-runner" || cmd.Name() == "uninstall-runner" {
+		return nil
+	}
+	engineKind := modelRunner.EngineKind()
+	if engineKind != types.ModelRunnerEngineKindMoby && engineKind != types.ModelRunnerEngineKindCloud {
+		return errors.New("--runner can only be used with a standalone model runner")
+	}
+	dockerClient, err := desktop.DockerClientForContext(dockerCLI, dockerCLI.CurrentContext())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	containerID, _, container, err := standalone.FindControllerContainer(cmd.Context(), dockerClient, runnerName)
+	if err != nil {
+		return fmt.Errorf("unable to identify standalone model runner %s: %w", runnerName, err)
+	} else if containerID == "" {
+		return fmt.Errorf("standalone model runner %s not found", runnerName)
+	}
+	if runner := inspectStandaloneRunner(container); runner.hostPort != 0 {
+		modelRunner.SetStandalonePort(runner.hostPort)
+	}
+	return nil
+}
+
 // ensureStandaloneRunnerAvailable is a utility function that other commands can
 // use to initialize a default standalone model runner. It is a no-op in
 // unsupported contexts or if automatic installs have been disabled.
@@ -101,7 +161,7 @@ func ensureStandaloneRunnerAvailable(ctx context.Context, printer standalone.Sta
 	}
 
 	// Check if a model runner container exists.
-	containerID, _, container, err := standalone.FindControllerContainer(ctx, dockerClient)
+	containerID, _, container, err := standalone.FindControllerContainer(ctx, dockerClient, runnerName)
 	if err != nil {
 		return nil, fmt.Errorf("unable to identify existing standalone model runner: %w", err)
 	} else if containerID != "" {
@@ -120,7 +180,7 @@ func ensureStandaloneRunnerAvailable(ctx context.Context, printer standalone.Sta
 	}
 
 	// Ensure that we have a model storage volume.
-	modelStorageVolume, err := standalone.EnsureModelStorageVolume(ctx, dockerClient, printer)
+	modelStorageVolume, err := standalone.EnsureModelStorageVolume(ctx, dockerClient, runnerName, printer)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize standalone model storage: %w", err)
 	}
@@ -132,7 +192,7 @@ func ensureStandaloneRunnerAvailable(ctx context.Context, printer standalone.Sta
 		port = standalone.DefaultControllerPortCloud
 		environment = "cloud"
 	}
-	if err := standalone.CreateControllerContainer(ctx, dockerClient, port, environment, false, gpu, modelStorageVolume, printer, engineKind); err != nil {
+	if err := standalone.CreateControllerContainer(ctx, dockerClient, runnerName, port, environment, false, gpu, modelStorageVolume, printer, engineKind, registriesConfigPath(), "", nil); err != nil {
 		return nil, fmt.Errorf("unable to initialize standalone model runner container: %w", err)
 	}
 
@@ -148,7 +208,7 @@ func ensureStandaloneRunnerAvailable(ctx context.Context, printer standalone.Sta
 	// return the container information), and probably pass the target
 	// information info waitForStandaloneRunnerAfterInstall, but let's wait
 	// until we do listener port customization / detection in the next PR.
-	containerID, _, container, err = standalone.FindControllerContainer(ctx, dockerClient)
+	containerID, _, container, err = standalone.FindControllerContainer(ctx, dockerClient, runnerName)
 	if err != nil {
 		return nil, fmt.Errorf("unable to identify existing standalone model runner: %w", err)
 	} else if containerID == "" {
@@ -161,6 +221,10 @@ func newInstallRunner() *cobra.Command {
 	var port uint16
 	var gpuMode string
 	var doNotTrack bool
+	var detach bool
+	var networkName string
+	var labelArgs []string
+	var containerName string
 	c := &cobra.Command{
 		Use:   "install-runner",
 		Short: "Install Docker Model Runner (Docker Engine only)",
@@ -175,10 +239,14 @@ func newInstallRunner() *cobra.Command {
 				cmd.Println("Use `docker desktop enable model-runner` instead")
 				return nil
 			} else if engineKind == types.ModelRunnerEngineKindMobyManual {
-				cmd.Println("Standalone installation not supported with MODEL_RUNNER_HOST set")
+				cmd.Println("Standalone installation not supported with MODEL_RUNNER_HOST or default-host set")
 				return nil
 			}
 
+			// Another instance most likely uses the default port already.
+			if containerName != "" && containerName != standalone.ControllerContainerName && port == 0 {
+				return fmt.Errorf("--name requires --port, so that the instances don't use the same port")
+			}
 			if port == 0 {
 				// Use "0" as a sentinel default flag value so it's not displayed automatically.
 				// The default values are written in the usage string.
@@ -197,6 +265,11 @@ func newInstallRunner() *cobra.Command {
 				port = standalone.DefaultControllerPortCloud
 			}
 
+			labels, err := parseLabels(labelArgs)
+			if err != nil {
+				return err
+			}
+
 			// Set the appropriate environment.
 			environment := "moby"
 			if engineKind == types.ModelRunnerEngineKindCloud {
@@ -210,7 +283,7 @@ func newInstallRunner() *cobra.Command {
 			}
 
 			// Check if an active model runner container already exists.
-			if ctrID, ctrName, _, err := standalone.FindControllerContainer(cmd.Context(), dockerClient); err != nil {
+			if ctrID, ctrName, _, err := standalone.FindControllerContainer(cmd.Context(), dockerClient, containerName); err != nil {
 				return err
 			} else if ctrID != "" {
 				if ctrName != "" {
@@ -240,23 +313,42 @@ func newInstallRunner() *cobra.Command {
 			}
 
 			// Ensure that we have a model storage volume.
-			modelStorageVolume, err := standalone.EnsureModelStorageVolume(cmd.Context(), dockerClient, cmd)
+			modelStorageVolume, err := standalone.EnsureModelStorageVolume(cmd.Context(), dockerClient, containerName, cmd)
 			if err != nil {
 				return fmt.Errorf("unable to initialize standalone model storage: %w", err)
 			}
 			// Create the model runner container.
-			if err := standalone.CreateControllerContainer(cmd.Context(), dockerClient, port, environment, doNotTrack, gpu, modelStorageVolume, cmd, engineKind); err != nil {
+			if err := standalone.CreateControllerContainer(cmd.Context(), dockerClient, containerName, port, environment, doNotTrack, gpu, modelStorageVolume, cmd, engineKind, registriesConfigPath(), networkName, labels); err != nil {
 				return fmt.Errorf("unable to initialize standalone model runner container: %w", err)
 			}
 
+			// Talk to the new container, even if it doesn't use the default
+			// port.
+			modelRunner.SetStandalonePort(port)
+
+			if detach {
+				// The caller is responsible for checking that the model
+				// runner is ready.
+				if containerName == "" {
+					containerName = standalone.ControllerContainerName
+				}
+				cmd.Printf("Model Runner container %s started on port %d (it may not be ready yet)\n",
+					containerName, port)
+				return nil
+			}
+
 			// Poll until we get a response from the model runner.
 			return waitForStandaloneRunnerAfterInstall(cmd.Context())
 		},
 		ValidArgsFunction: completion.NoComplete,
 	}
+	c.Flags().BoolVarP(&detach, "detach", "d", false, "Return once the container is started, without waiting for the model runner to be ready")
 	c.Flags().Uint16Var(&port, "port", 0,
 		"Docker container port for Docker Model Runner (default: 12434 for Docker CE, 12435 for Cloud mode)")
 	c.Flags().StringVar(&gpuMode, "gpu", "auto", "Specify GPU support (none|auto|cuda)")
+	c.Flags().StringVar(&containerName, "name", "", "Name of the Docker Model Runner container, to run several instances side by side, each with its own port and model storage (default \""+standalone.ControllerContainerName+"\")")
+	c.Flags().StringVar(&networkName, "network", "", "Connect the Docker Model Runner container to the given network")
+	c.Flags().StringArrayVar(&labelArgs, "label", nil, "Set additional labels on the Docker Model Runner container (key=value)")
 	c.Flags().BoolVar(&doNotTrack, "do-not-track", false, "Do not track models usage in Docker Model Runner")
 	return c
 }
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels([]string{"team=ml", "env=prod", "pinned"})
+	if err != nil {
+		t.Fatalf("parseLabels() error = %v", err)
+	}
+	expected := map[string]string{"team": "ml", "env": "prod", "pinned": ""}
+	if !maps.Equal(labels, expected) {
+		t.Errorf("parseLabels() = %v, want %v", labels, expected)
+	}
+
+	for _, arg := range []string{"=value", "com.docker.model-runner.role=other", "com.docker.desktop.service=x"} {
+		if _, err := parseLabels([]string{arg}); err == nil {
+			t.Errorf("parseLabels(%q) should return an error", arg)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"slices"
 	"strings"
 	"time"
@@ -13,19 +15,28 @@ import (
 	"github.com/docker/model-cli/commands/formatter"
 	"github.com/docker/model-cli/desktop"
 	"github.com/docker/model-cli/pkg/standalone"
-	dmrm "github.com/docker/model-runner/pkg/inference/models"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 func newListCmd() *cobra.Command {
-	var jsonFormat, openai, quiet bool
+	var openai, quiet, showLoaded, noTrunc bool
 	var backend string
+	var filterArgs []string
+	var since, before string
+	var format string
+	var page listPage
 	c := &cobra.Command{
 		Use:     "list [OPTIONS]",
 		Aliases: []string{"ls"},
 		Short:   "List the models pulled to your local environment",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			jsonFormat := jsonOutput
+			backend, err := resolveBackend(cmd, backend)
+			if err != nil {
+				return err
+			}
+
 			// Validate backend if specified
 			if backend != "" {
 				if err := validateBackend(backend); err != nil {
@@ -37,6 +48,59 @@ func newListCmd() *cobra.Command {
 				return fmt.Errorf("--quiet flag cannot be used with --openai flag or OpenAI backend")
 			}
 
+			if (backend == "openai" || openai) && (len(filterArgs) > 0 || since != "" || before != "") {
+				return fmt.Errorf("--filter, --since, and --before flags cannot be used with --openai flag or OpenAI backend")
+			}
+
+			if page.limit < 0 || page.offset < 0 {
+				return fmt.Errorf("--limit and --offset must not be negative")
+			}
+			if (backend == "openai" || openai) && (page.limit > 0 || page.offset > 0) {
+				return fmt.Errorf("--limit and --offset flags cannot be used with --openai flag or OpenAI backend")
+			}
+
+			if showLoaded && (backend == "openai" || openai || quiet) {
+				return fmt.Errorf("--show-loaded flag cannot be used with --openai or --quiet flags or OpenAI backend")
+			}
+
+			if quiet && jsonFormat {
+				return fmt.Errorf("--quiet cannot be used with --json")
+			}
+
+			var tmpl *formatter.Template
+			if format == "json" {
+				if quiet {
+					return fmt.Errorf("--format json cannot be used with --quiet")
+				}
+				jsonFormat = true
+			} else if format != "" {
+				if openai || backend == "openai" || quiet || jsonFormat {
+					return fmt.Errorf("--format flag cannot be used with --openai, --quiet, or --json flags or OpenAI backend")
+				}
+				if tmpl, err = formatter.ParseTemplate(format); err != nil {
+					return err
+				}
+				if noTrunc {
+					tmpl.NoTrunc()
+				}
+			}
+
+			filters, err := parseModelFilters(filterArgs)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			if since != "" {
+				if filters.since, err = parseTimeFilter(since, now); err != nil {
+					return fmt.Errorf("invalid --since: %w", err)
+				}
+			}
+			if before != "" {
+				if filters.before, err = parseTimeFilter(before, now); err != nil {
+					return fmt.Errorf("invalid --before: %w", err)
+				}
+			}
+
 			// Validate API key for OpenAI backend
 			apiKey, err := ensureAPIKey(backend)
 			if err != nil {
@@ -46,7 +110,7 @@ func newListCmd() *cobra.Command {
 			// If we're doing an automatic install, only show the installation
 			// status if it won't corrupt machine-readable output.
 			var standaloneInstallPrinter standalone.StatusPrinter
-			if !jsonFormat && !openai && !quiet && backend == "" {
+			if !jsonFormat && !openai && !quiet && format == "" && backend == "" {
 				standaloneInstallPrinter = cmd
 			}
 			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), standaloneInstallPrinter); err != nil {
@@ -56,7 +120,7 @@ func newListCmd() *cobra.Command {
 			if len(args) > 0 {
 				modelFilter = args[0]
 			}
-			models, err := listModels(openai, backend, desktopClient, quiet, jsonFormat, apiKey, modelFilter)
+			models, err := listModels(openai, backend, desktopClient, quiet, jsonFormat, tmpl, apiKey, modelFilter, filters, page, showLoaded, noTrunc)
 			if err != nil {
 				return err
 			}
@@ -65,15 +129,22 @@ func newListCmd() *cobra.Command {
 		},
 		ValidArgsFunction: completion.ModelNamesAndTags(getDesktopClient, 1),
 	}
-	c.Flags().BoolVar(&jsonFormat, "json", false, "List models in a JSON format")
 	c.Flags().BoolVar(&openai, "openai", false, "List models in an OpenAI format")
 	c.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only show model IDs")
+	c.Flags().StringArrayVarP(&filterArgs, "filter", "f", nil, "Filter output based on conditions provided (e.g. reference=ai/*, label=key=value)")
+	c.Flags().StringVar(&format, "format", "", "Format the output as json or using the given Go template (e.g. '{{truncate .ID 19}}')")
+	c.Flags().StringVar(&since, "since", "", "Only show models created after the given duration (e.g. 24h) or timestamp (e.g. 2024-01-01)")
+	c.Flags().StringVar(&before, "before", "", "Only show models created before the given duration (e.g. 24h) or timestamp (e.g. 2024-01-01)")
+	c.Flags().IntVar(&page.limit, "limit", 0, "Show at most the given number of models (0 for all)")
+	c.Flags().IntVar(&page.offset, "offset", 0, "Skip the given number of models before listing the others")
+	c.Flags().BoolVar(&noTrunc, "no-trunc", false, "Don't truncate output")
+	c.Flags().BoolVar(&showLoaded, "show-loaded", false, "Show which models are loaded in a backend")
 	c.Flags().StringVar(&backend, "backend", "", fmt.Sprintf("Specify the backend to use (%s)", ValidBackendsKeys()))
 	c.Flags().MarkHidden("backend")
 	return c
 }
 
-func listModels(openai bool, backend string, desktopClient *desktop.Client, quiet bool, jsonFormat bool, apiKey string, modelFilter string) (string, error) {
+func listModels(openai bool, backend string, desktopClient *desktop.Client, quiet bool, jsonFormat bool, tmpl *formatter.Template, apiKey string, modelFilter string, filters modelFilters, page listPage, showLoaded, noTrunc bool) (string, error) {
 	if openai || backend == "openai" {
 		models, err := desktopClient.ListOpenAI(backend, apiKey)
 		if err != nil {
@@ -89,7 +160,7 @@ func listModels(openai bool, backend string, desktopClient *desktop.Client, quie
 	}
 
 	if modelFilter != "" {
-		var filteredModels []dmrm.Model
+		var filteredModels []desktop.Model
 		for _, m := range models {
 			hasMatchingTag := false
 			for _, tag := range m.Tags {
@@ -106,7 +177,39 @@ func listModels(openai bool, backend string, desktopClient *desktop.Client, quie
 		models = filteredModels
 	}
 
+	if !filters.empty() {
+		var filteredModels []desktop.Model
+		for _, m := range models {
+			if filters.match(m) {
+				filteredModels = append(filteredModels, m)
+			}
+		}
+		models = filteredModels
+	}
+
+	if page.limit == 0 && !jsonFormat && !quiet && tmpl == nil && len(models) > largeModelListThreshold {
+		fmt.Fprintf(os.Stderr, "Listing %d models; use --limit and --offset to show fewer at a time\n", len(models))
+	}
+	models = page.apply(models)
+
+	// The running models are only queried when needed.
+	var loaded map[string]bool
+	if showLoaded {
+		ps, err := desktopClient.PS()
+		if err != nil {
+			err = handleClientError(err, "Failed to list running models")
+			return "", handleNotRunningError(err)
+		}
+		loaded = make(map[string]bool)
+		for _, m := range models {
+			loaded[m.ID] = modelLoaded(m, ps)
+		}
+	}
+
 	if jsonFormat {
+		if showLoaded {
+			return formatter.ToStandardJSON(withLoaded(models, loaded))
+		}
 		return formatter.ToStandardJSON(models)
 	}
 	if quiet {
@@ -116,24 +219,220 @@ func listModels(openai bool, backend string, desktopClient *desktop.Client, quie
 				fmt.Fprintf(os.Stderr, "invalid image ID for model: %v\n", m)
 				continue
 			}
+			if noTrunc {
+				modelIDs += m.ID + "\n"
+				continue
+			}
 			modelIDs += fmt.Sprintf("%s\n", m.ID[7:19])
 		}
 		return modelIDs, nil
 	}
-	return prettyPrintModels(models), nil
+	if tmpl != nil {
+		var output strings.Builder
+		for _, m := range models {
+			var data any = m
+			if showLoaded {
+				data = listedModel{Model: m, Loaded: loaded[m.ID]}
+			}
+			line, err := tmpl.Execute(data)
+			if err != nil {
+				return "", err
+			}
+			output.WriteString(line)
+		}
+		return output.String(), nil
+	}
+	return prettyPrintModels(models, loaded, noTrunc), nil
+}
+
+// listedModel is a model along with whether it's loaded, as listed by
+// --show-loaded.
+type listedModel struct {
+	desktop.Model
+	Loaded bool
+}
+
+// MarshalJSON adds the loaded field to the model's own encoding, which would
+// otherwise replace that of listedModel.
+func (m listedModel) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(m.Model)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["loaded"], _ = json.Marshal(m.Loaded)
+	return json.Marshal(fields)
+}
+
+func withLoaded(models []desktop.Model, loaded map[string]bool) []listedModel {
+	listed := make([]listedModel, 0, len(models))
+	for _, m := range models {
+		listed = append(listed, listedModel{Model: m, Loaded: loaded[m.ID]})
+	}
+	return listed
+}
+
+// modelLoaded reports whether a model is loaded in any backend. Backends
+// report the model by the reference it was loaded with, which may be its ID or
+// any of its tags, with or without the default tag.
+func modelLoaded(m desktop.Model, ps []desktop.BackendStatus) bool {
+	return slices.ContainsFunc(ps, func(status desktop.BackendStatus) bool {
+		return status.ModelName == m.ID || slices.Contains(m.Tags, status.ModelName) ||
+			slices.Contains(m.Tags, status.ModelName+":latest")
+	})
+}
+
+// largeModelListThreshold is the number of models above which ls suggests
+// paging through them.
+const largeModelListThreshold = 200
+
+// listPage selects the models shown by --limit and --offset.
+type listPage struct {
+	limit, offset int
+}
+
+// apply returns the models on the page. Models are counted rather than table
+// rows, so a model with several tags is never split across pages.
+func (p listPage) apply(models []desktop.Model) []desktop.Model {
+	models = models[min(p.offset, len(models)):]
+	if p.limit > 0 && p.limit < len(models) {
+		models = models[:p.limit]
+	}
+	return models
+}
+
+// modelFilters holds the conditions supplied via --filter. Different filter
+// keys are AND'ed together. Multiple reference patterns are OR'ed, while
+// multiple label conditions must all hold, as with `docker images`.
+type modelFilters struct {
+	// references are glob patterns matched against model tags.
+	references []string
+	// labels are label conditions, either "key" or "key=value".
+	labels []string
+	// since and before, if set, bound the creation time of models.
+	since, before time.Time
+}
+
+// parseModelFilters parses key=value filter arguments.
+func parseModelFilters(args []string) (modelFilters, error) {
+	var filters modelFilters
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return modelFilters{}, fmt.Errorf("invalid filter %q: expected key=value", arg)
+		}
+		switch key {
+		case "reference":
+			if _, err := path.Match(value, ""); err != nil {
+				return modelFilters{}, fmt.Errorf("invalid reference filter %q: %w", value, err)
+			}
+			filters.references = append(filters.references, value)
+		case "label":
+			filters.labels = append(filters.labels, value)
+		default:
+			return modelFilters{}, fmt.Errorf("invalid filter %q: unsupported key %q", arg, key)
+		}
+	}
+	return filters, nil
+}
+
+// empty reports whether no filters were provided.
+func (f modelFilters) empty() bool {
+	return len(f.references) == 0 && len(f.labels) == 0 && f.since.IsZero() && f.before.IsZero()
+}
+
+// match reports whether a model satisfies all provided filters.
+func (f modelFilters) match(m desktop.Model) bool {
+	if len(f.references) > 0 && !matchesReference(m, f.references) {
+		return false
+	}
+	for _, label := range f.labels {
+		if !matchesLabel(m, label) {
+			return false
+		}
+	}
+	created := time.Unix(m.Created, 0)
+	if !f.since.IsZero() && !created.After(f.since) {
+		return false
+	}
+	if !f.before.IsZero() && !created.Before(f.before) {
+		return false
+	}
+	return true
+}
+
+// timeFilterLayouts are the absolute timestamp formats accepted by --since and
+// --before.
+var timeFilterLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// parseTimeFilter parses either a duration, which is relative to now, or an
+// absolute timestamp. Timestamps without a time zone are in local time.
+func parseTimeFilter(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range timeFilterLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected a duration (e.g. 24h) or a timestamp (e.g. 2024-01-01) (got %q)", value)
+}
+
+// matchesLabel reports whether the model carries the label condition, which is
+// either a bare key or a key=value pair.
+func matchesLabel(m desktop.Model, condition string) bool {
+	key, value, hasValue := strings.Cut(condition, "=")
+	actual, ok := m.Labels[key]
+	if !ok {
+		return false
+	}
+	return !hasValue || actual == value
+}
+
+// matchesReference reports whether any of the model's tags matches any of the
+// glob patterns. Patterns are matched against both the full tag and the
+// repository portion without the tag, mirroring `docker images REPO`.
+func matchesReference(m desktop.Model, patterns []string) bool {
+	for _, tag := range m.Tags {
+		repo := tag
+		if i := strings.LastIndex(tag, ":"); i > strings.LastIndex(tag, "/") {
+			repo = tag[:i]
+		}
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, tag); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, repo); ok {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func prettyPrintModels(models []dmrm.Model) string {
+// prettyPrintModels renders models as a table. If loaded isn't nil, a LOADED
+// column marks the models that are loaded in a backend. With noTrunc, full
+// IDs are shown and long values aren't wrapped.
+func prettyPrintModels(models []desktop.Model, loaded map[string]bool, noTrunc bool) string {
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
 
-	table.SetHeader([]string{"MODEL NAME", "PARAMETERS", "QUANTIZATION", "ARCHITECTURE", "MODEL ID", "CREATED", "SIZE"})
+	header := []string{"MODEL NAME", "PARAMETERS", "QUANTIZATION", "ARCHITECTURE", "MODEL ID", "CREATED", "SIZE"}
+	if loaded != nil {
+		header = append(header, "LOADED")
+	}
+	table.SetHeader(header)
 
 	table.SetBorder(false)
 	table.SetColumnSeparator("")
 	table.SetHeaderLine(false)
 	table.SetTablePadding("  ")
 	table.SetNoWhiteSpace(true)
+	table.SetAutoWrapText(!noTrunc)
 
 	table.SetColumnAlignment([]int{
 		tablewriter.ALIGN_LEFT, // MODEL
@@ -143,16 +442,17 @@ func prettyPrintModels(models []dmrm.Model) string {
 		tablewriter.ALIGN_LEFT, // MODEL ID
 		tablewriter.ALIGN_LEFT, // CREATED
 		tablewriter.ALIGN_LEFT, // SIZE
+		tablewriter.ALIGN_LEFT, // LOADED
 	})
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 
 	for _, m := range models {
 		if len(m.Tags) == 0 {
-			appendRow(table, "<none>", m)
+			appendRow(table, "<none>", m, loaded, noTrunc)
 			continue
 		}
 		for _, tag := range m.Tags {
-			appendRow(table, tag, m)
+			appendRow(table, tag, m, loaded, noTrunc)
 		}
 	}
 
@@ -160,18 +460,30 @@ func prettyPrintModels(models []dmrm.Model) string {
 	return buf.String()
 }
 
-func appendRow(table *tablewriter.Table, tag string, model dmrm.Model) {
+func appendRow(table *tablewriter.Table, tag string, model desktop.Model, loaded map[string]bool, noTrunc bool) {
 	if len(model.ID) < 19 {
 		fmt.Fprintf(os.Stderr, "invalid model ID for model: %v\n", model)
 		return
 	}
-	table.Append([]string{
+	id := model.ID[7:19]
+	if noTrunc {
+		id = model.ID
+	}
+	row := []string{
 		tag,
 		model.Config.Parameters,
 		model.Config.Quantization,
 		model.Config.Architecture,
-		model.ID[7:19],
+		id,
 		units.HumanDuration(time.Since(time.Unix(model.Created, 0))) + " ago",
 		model.Config.Size,
-	})
+	}
+	if loaded != nil {
+		mark := ""
+		if loaded[model.ID] {
+			mark = "yes"
+		}
+		row = append(row, mark)
+	}
+	table.Append(row)
 }
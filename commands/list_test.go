@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/docker/model-cli/desktop"
+	dmrm "github.com/docker/model-runner/pkg/inference/models"
+)
+
+func TestModelFiltersReference(t *testing.T) {
+	models := []desktop.Model{
+		{Model: dmrm.Model{ID: "sha256:1111111111111111111111111111", Tags: []string{"ai/smollm2:latest"}}},
+		{Model: dmrm.Model{ID: "sha256:2222222222222222222222222222", Tags: []string{"ai/llama3.2:1B-Q8_0"}}},
+		{Model: dmrm.Model{ID: "sha256:3333333333333333333333333333", Tags: []string{"myorg/custom:v1"}}},
+		{Model: dmrm.Model{ID: "sha256:4444444444444444444444444444", Tags: []string{"localhost:5000/ai/gemma3"}}},
+	}
+
+	tests := []struct {
+		name     string
+		filters  []string
+		expected []string
+	}{
+		{
+			name:     "glob on namespace",
+			filters:  []string{"reference=ai/*"},
+			expected: []string{"sha256:1111111111111111111111111111", "sha256:2222222222222222222222222222"},
+		},
+		{
+			name:     "repository without tag",
+			filters:  []string{"reference=ai/smollm2"},
+			expected: []string{"sha256:1111111111111111111111111111"},
+		},
+		{
+			name:     "full tag",
+			filters:  []string{"reference=ai/llama3.2:1B-*"},
+			expected: []string{"sha256:2222222222222222222222222222"},
+		},
+		{
+			name:     "multiple patterns are OR'ed",
+			filters:  []string{"reference=ai/smollm2", "reference=myorg/*"},
+			expected: []string{"sha256:1111111111111111111111111111", "sha256:3333333333333333333333333333"},
+		},
+		{
+			name:     "registry port is not treated as a tag",
+			filters:  []string{"reference=localhost:5000/ai/*"},
+			expected: []string{"sha256:4444444444444444444444444444"},
+		},
+		{
+			name:     "no match",
+			filters:  []string{"reference=other/*"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := parseModelFilters(tt.filters)
+			if err != nil {
+				t.Fatalf("parseModelFilters() error = %v", err)
+			}
+			var matched []string
+			for _, m := range models {
+				if filters.match(m) {
+					matched = append(matched, m.ID)
+				}
+			}
+			if len(matched) != len(tt.expected) {
+				t.Fatalf("matched %v, want %v", matched, tt.expected)
+			}
+			for i := range matched {
+				if matched[i] != tt.expected[i] {
+					t.Errorf("matched %v, want %v", matched, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestModelFiltersLabel(t *testing.T) {
+	m := desktop.Model{
+		Model:  dmrm.Model{ID: "sha256:1111111111111111111111111111", Tags: []string{"ai/smollm2:latest"}},
+		Labels: map[string]string{"team": "search", "purpose": "eval"},
+	}
+	unlabeled := desktop.Model{
+		Model: dmrm.Model{ID: "sha256:2222222222222222222222222222", Tags: []string{"ai/smollm2:360M"}},
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		match   bool
+	}{
+		{name: "key only", filters: []string{"label=team"}, match: true},
+		{name: "key and value", filters: []string{"label=team=search"}, match: true},
+		{name: "wrong value", filters: []string{"label=team=infra"}, match: false},
+		{name: "all labels must match", filters: []string{"label=team=search", "label=owner"}, match: false},
+		{name: "combined with reference", filters: []string{"label=purpose=eval", "reference=ai/*"}, match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := parseModelFilters(tt.filters)
+			if err != nil {
+				t.Fatalf("parseModelFilters() error = %v", err)
+			}
+			if got := filters.match(m); got != tt.match {
+				t.Errorf("match() = %v, want %v", got, tt.match)
+			}
+			if filters.match(unlabeled) {
+				t.Errorf("match() should not match a model without labels")
+			}
+		})
+	}
+}
+
+func TestParseModelFiltersInvalid(t *testing.T) {
+	for _, arg := range []string{"reference", "reference=", "unknown=value", "reference=[ai"} {
+		if _, err := parseModelFilters([]string{arg}); err == nil {
+			t.Errorf("parseModelFilters(%q) should return an error", arg)
+		}
+	}
+}
+
+func TestModelFiltersCreated(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := desktop.Model{Model: dmrm.Model{ID: "sha256:1111111111111111111111111111", Created: now.Add(-time.Hour).Unix()}}
+	old := desktop.Model{Model: dmrm.Model{ID: "sha256:2222222222222222222222222222", Created: now.Add(-72 * time.Hour).Unix()}}
+
+	since, err := parseTimeFilter("24h", now)
+	if err != nil {
+		t.Fatalf("parseTimeFilter() error = %v", err)
+	}
+	filters := modelFilters{since: since}
+	if !filters.match(recent) || filters.match(old) {
+		t.Errorf("--since 24h should only match the recent model")
+	}
+
+	before, err := parseTimeFilter("2024-05-30T00:00:00Z", now)
+	if err != nil {
+		t.Fatalf("parseTimeFilter() error = %v", err)
+	}
+	filters = modelFilters{before: before}
+	if filters.match(recent) || !filters.match(old) {
+		t.Errorf("--before 2024-05-30 should only match the old model")
+	}
+
+	if _, err := parseTimeFilter("yesterday", now); err == nil {
+		t.Errorf("parseTimeFilter(%q) should return an error", "yesterday")
+	}
+}
+
+func TestListPage(t *testing.T) {
+	models := []desktop.Model{
+		{Model: dmrm.Model{ID: "a"}},
+		{Model: dmrm.Model{ID: "b"}},
+		{Model: dmrm.Model{ID: "c"}},
+	}
+
+	tests := []struct {
+		name     string
+		page     listPage
+		expected []string
+	}{
+		{name: "all", expected: []string{"a", "b", "c"}},
+		{name: "limit", page: listPage{limit: 2}, expected: []string{"a", "b"}},
+		{name: "offset", page: listPage{offset: 1}, expected: []string{"b", "c"}},
+		{name: "limit and offset", page: listPage{limit: 1, offset: 1}, expected: []string{"b"}},
+		{name: "limit beyond end", page: listPage{limit: 5, offset: 2}, expected: []string{"c"}},
+		{name: "offset beyond end", page: listPage{offset: 5}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			for _, m := range tt.page.apply(models) {
+				ids = append(ids, m.ID)
+			}
+			if !slices.Equal(ids, tt.expected) {
+				t.Errorf("apply() = %v, want %v", ids, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelLoaded(t *testing.T) {
+	model := desktop.Model{Model: dmrm.Model{ID: "sha256:354bf30d0aa3", Tags: []string{"ai/smollm2:latest"}}}
+	tests := []struct {
+		name     string
+		loaded   string
+		expected bool
+	}{
+		{name: "tag", loaded: "ai/smollm2:latest", expected: true},
+		{name: "default tag", loaded: "ai/smollm2", expected: true},
+		{name: "id", loaded: "sha256:354bf30d0aa3", expected: true},
+		{name: "other model", loaded: "ai/qwen3", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := []desktop.BackendStatus{{BackendName: "llama.cpp", ModelName: tt.loaded}}
+			if got := modelLoaded(model, ps); got != tt.expected {
+				t.Errorf("modelLoaded() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListedModelJSON(t *testing.T) {
+	model := desktop.Model{Model: dmrm.Model{ID: "sha256:354bf30d0aa3", Tags: []string{"ai/smollm2:latest"}}}
+	data, err := json.Marshal(listedModel{Model: model, Loaded: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["loaded"] != true || fields["id"] != model.ID {
+		t.Errorf("json.Marshal() = %s, want the model's fields and loaded", data)
+	}
+}
+
+func TestListQuietWithJSON(t *testing.T) {
+	jsonOutput = true
+	defer func() { jsonOutput = false }()
+
+	cmd := newListCmd()
+	cmd.SetArgs([]string{"--quiet", "--backend", "llama.cpp"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	const want = "--quiet cannot be used with --json"
+	if err := cmd.Execute(); err == nil || err.Error() != want {
+		t.Errorf("Execute() error = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/model-cli/desktop"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/cobra"
+)
+
+func newLoadCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "load FILE",
+		Short: "Load the models of a bundle file created with docker model save",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(
+					"'docker model load' requires 1 argument.\n\n" +
+						"Usage:  docker model load FILE\n\n" +
+						"See 'docker model load --help' for more information",
+				)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
+				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
+			}
+			return loadBundle(cmd, desktopClient, args[0])
+		},
+	}
+	return c
+}
+
+// loadBundle loads the models of a bundle into the model runner and tags
+// them. Failing to load one model doesn't prevent loading the others.
+func loadBundle(cmd *cobra.Command, desktopClient *desktop.Client, path string) error {
+	if f, err := os.Open(path); err == nil {
+		if format := bundleCompression(f); format != "" {
+			cmd.PrintErrf("Decompressing %s (%s)...\n", path, format)
+		}
+		f.Close()
+	}
+	bundle, err := openBundle(path)
+	if err != nil {
+		return err
+	}
+	defer bundle.Close()
+
+	counted := make(map[v1.Hash]bool)
+	var total uint64
+	for _, model := range bundle.index.Models {
+		_, manifest, err := bundle.manifest(model)
+		if err != nil {
+			return fmt.Errorf("unable to read bundle %s: %w", path, err)
+		}
+		for _, desc := range manifestBlobs(manifest) {
+			if !counted[desc.Digest] {
+				counted[desc.Digest] = true
+				total += uint64(desc.Size)
+			}
+		}
+	}
+
+	printProgress, inPlace, err := progressPrinter()
+	if err != nil {
+		return err
+	}
+	step := 1
+	if !inPlace {
+		step = pullProgressStep
+	}
+	progress, flush := steppedProgress(step, printProgress)
+	var loaded uint64
+	report := func(n int) {
+		loaded += uint64(n)
+		progress(desktop.PullProgress{
+			Message: fmt.Sprintf("Loading: %s of %s", formatSize(int64(loaded)), formatSize(int64(total))),
+			Current: loaded,
+			Total:   total,
+		})
+	}
+
+	// Blobs that were loaded with an earlier model are already in the model
+	// runner's store, and aren't sent again.
+	sent := make(map[v1.Hash]bool)
+	results := make([]error, len(bundle.index.Models))
+	for i, model := range bundle.index.Models {
+		results[i] = loadBundledModel(cmd.Context(), desktopClient, bundle, model, sent, report)
+		if cmd.Context().Err() != nil {
+			break
+		}
+	}
+	flush()
+	if inPlace {
+		cmd.Println()
+	}
+	if err := cmd.Context().Err(); err != nil {
+		return err
+	}
+
+	var failed []string
+	for i, model := range bundle.index.Models {
+		if results[i] != nil {
+			cmd.PrintErrf("Failed to load %s: %v\n", model.Reference, results[i])
+			failed = append(failed, model.Reference)
+			continue
+		}
+		cmd.Printf("Loaded %s (%s)\n", model.Reference, model.Manifest)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to load %d of %d model(s): %s", len(failed), len(bundle.index.Models), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// loadBundledModel loads a model of a bundle into the model runner and tags
+// it with its reference. The blobs it sends are added to sent.
+func loadBundledModel(ctx context.Context, desktopClient *desktop.Client, bundle *bundleReader, model bundleModel, sent map[v1.Hash]bool, report func(int)) error {
+	_, manifest, err := bundle.manifest(model)
+	if err != nil {
+		return err
+	}
+	pr, pw := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		err := bundle.writeModelArchive(pw, model, sent, report)
+		pw.CloseWithError(err)
+		errCh <- err
+	}()
+	loadErr := desktopClient.LoadModel(ctx, pr)
+	pr.Close()
+	writeErr := <-errCh
+	if loadErr != nil {
+		return handleNotRunningError(handleClientError(loadErr, "Failed to load model"))
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	for _, desc := range manifestBlobs(manifest) {
+		sent[desc.Digest] = true
+	}
+
+	// Models saved by digest have no tag to apply.
+	tag, err := name.NewTag(model.Reference)
+	if err != nil {
+		return nil
+	}
+	if err := desktopClient.Tag(model.Manifest, parseRepo(tag), tag.TagStr()); err != nil {
+		return fmt.Errorf("failed to tag the model: %w", err)
+	}
+	return nil
+}
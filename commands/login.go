@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/docker/cli/cli/config/configfile"
+	clitypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/standalone"
+	"github.com/docker/model-cli/pkg/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// dockerHubServerAddress is the address under which Docker Hub credentials are
+// stored in Docker config files.
+const dockerHubServerAddress = "https://index.docker.io/v1/"
+
+func newLoginCmd() *cobra.Command {
+	var username, password string
+	var passwordStdin bool
+	c := &cobra.Command{
+		Use:   "login [OPTIONS] [REGISTRY]",
+		Short: "Log in to a registry for model operations",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureOnline("log in to a registry"); err != nil {
+				return err
+			}
+			registry, serverAddress, err := parseLoginRegistry(args)
+			if err != nil {
+				return err
+			}
+			if password != "" && passwordStdin {
+				return errors.New("--password and --password-stdin are mutually exclusive")
+			}
+			if passwordStdin {
+				if username == "" {
+					return errors.New("must provide --username with --password-stdin")
+				}
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("unable to read password from stdin: %w", err)
+				}
+				password = strings.TrimRight(string(data), "\r\n")
+			}
+			if username == "" || password == "" {
+				if username, password, err = promptForCredentials(cmd, username); err != nil {
+					return err
+				}
+			}
+			auth := clitypes.AuthConfig{ServerAddress: serverAddress, Username: username, Password: password}
+			if err := checkRegistryCredentials(cmd, registry, auth); err != nil {
+				return err
+			}
+			cf, err := loadRegistriesConfigFile()
+			if err != nil {
+				return err
+			}
+			if err := cf.GetCredentialsStore(serverAddress).Store(auth); err != nil {
+				return fmt.Errorf("unable to store credentials: %w", err)
+			}
+			cmd.Println("Login Succeeded")
+			return syncRunnerCredentials(cmd, cf)
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	c.Flags().StringVarP(&username, "username", "u", "", "Username")
+	c.Flags().StringVarP(&password, "password", "p", "", "Password or personal access token")
+	c.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Take the password from stdin")
+	return c
+}
+
+func newLogoutCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "logout [REGISTRY]",
+		Short: "Log out from a registry used for model operations",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, serverAddress, err := parseLoginRegistry(args)
+			if err != nil {
+				return err
+			}
+			cf, err := loadRegistriesConfigFile()
+			if err != nil {
+				return err
+			}
+			if _, ok := cf.AuthConfigs[serverAddress]; !ok && cf.CredentialsStore == "" && cf.CredentialHelpers[serverAddress] == "" {
+				cmd.Printf("Not logged in to %s\n", serverAddress)
+				return nil
+			}
+			if err := cf.GetCredentialsStore(serverAddress).Erase(serverAddress); err != nil {
+				return fmt.Errorf("unable to remove credentials for %s: %w", serverAddress, err)
+			}
+			cmd.Printf("Removing login credentials for %s\n", serverAddress)
+			return syncRunnerCredentials(cmd, cf)
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	return c
+}
+
+// parseLoginRegistry returns the registry passed to login or logout, which
+// defaults to Docker Hub, and the address that its credentials are stored
+// under.
+func parseLoginRegistry(args []string) (name.Registry, string, error) {
+	if len(args) == 0 {
+		registry, err := name.NewRegistry(name.DefaultRegistry)
+		return registry, dockerHubServerAddress, err
+	}
+	address := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(args[0], "https://"), "http://"), "/")
+	registry, err := name.NewRegistry(address)
+	if err != nil {
+		return name.Registry{}, "", fmt.Errorf("invalid registry %q: %w", args[0], err)
+	}
+	if registry.RegistryStr() == name.DefaultRegistry {
+		return registry, dockerHubServerAddress, nil
+	}
+	return registry, registry.RegistryStr(), nil
+}
+
+// promptForCredentials asks for the credentials that weren't specified via
+// flags.
+func promptForCredentials(cmd *cobra.Command, username string) (string, string, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", "", errors.New("cannot prompt for credentials: stdin is not a terminal (use --username and --password-stdin)")
+	}
+	if username == "" {
+		cmd.Print("Username: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return "", "", fmt.Errorf("unable to read username: %w", err)
+		}
+		if username = strings.TrimSpace(line); username == "" {
+			return "", "", errors.New("username is required")
+		}
+	}
+	cmd.Print("Password: ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	cmd.Println()
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read password: %w", err)
+	}
+	if len(password) == 0 {
+		return "", "", errors.New("password is required")
+	}
+	return username, string(password), nil
+}
+
+// checkRegistryCredentials verifies that the registry accepts auth.
+func checkRegistryCredentials(cmd *cobra.Command, registry name.Registry, auth clitypes.AuthConfig) error {
+	authenticator := authn.FromConfig(authn.AuthConfig{Username: auth.Username, Password: auth.Password})
+	rt, err := transport.NewWithContext(cmd.Context(), registry, authenticator, http.DefaultTransport, nil)
+	if err != nil {
+		return fmt.Errorf("login to %s failed: %w", registry.RegistryStr(), err)
+	}
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet,
+		fmt.Sprintf("%s://%s/v2/", registry.Scheme(), registry.RegistryStr()), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return fmt.Errorf("login to %s failed: %w", registry.RegistryStr(), err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("login to %s failed: %s", registry.RegistryStr(), resp.Status)
+	}
+	return nil
+}
+
+// loadRegistriesConfigFile loads the Docker config file that holds the
+// registry credentials for model operations. A registries config that doesn't
+// exist yet is created when credentials are stored.
+func loadRegistriesConfigFile() (*configfile.ConfigFile, error) {
+	path := registriesConfigPath()
+	if path == "" {
+		return dockerCLI.ConfigFile(), nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return configfile.New(path), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to use registries config: %w", err)
+	}
+	defer f.Close()
+	cf := configfile.New(path)
+	if err := cf.LoadFromReader(f); err != nil {
+		return nil, fmt.Errorf("unable to use registries config %s: %w", path, err)
+	}
+	return cf, nil
+}
+
+// syncRunnerCredentials copies the updated credentials into the standalone
+// model runner, if there is one. Other model runners read the credentials
+// from the host directly.
+func syncRunnerCredentials(cmd *cobra.Command, cf *configfile.ConfigFile) error {
+	engineKind := modelRunner.EngineKind()
+	if engineKind != types.ModelRunnerEngineKindMoby {
+		return nil
+	}
+	if cf.CredentialsStore != "" {
+		cmd.PrintErrf("Warning: credentials are kept by the %q credential helper, which the standalone model runner can't use; "+
+			"use --registries-config to store them in a file instead\n", cf.CredentialsStore)
+	}
+	dockerClient, err := desktop.DockerClientForContext(dockerCLI, dockerCLI.CurrentContext())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	ctrID, _, _, err := standalone.FindControllerContainer(cmd.Context(), dockerClient, runnerName)
+	if err != nil {
+		return fmt.Errorf("unable to identify Model Runner container: %w", err)
+	} else if ctrID == "" {
+		// The credentials are copied when the runner is installed.
+		return nil
+	}
+	if err := standalone.RefreshControllerDockerConfig(cmd.Context(), dockerClient, ctrID, engineKind, registriesConfigPath()); err != nil {
+		return fmt.Errorf("unable to update the model runner's credentials: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestFileName is the name of the project manifest that run reads from the
+// working directory.
+const manifestFileName = ".model.yaml"
+
+// runManifest holds project defaults for run. Command-line arguments and flags
+// take precedence over the manifest.
+type runManifest struct {
+	// Model is the model to run if none is specified.
+	Model string `yaml:"model"`
+	// Backend is the backend to use if --backend isn't specified.
+	Backend string `yaml:"backend"`
+	// ContextSize is the context size (in tokens) to configure for Model.
+	ContextSize int64 `yaml:"context-size"`
+	// RuntimeFlags are the runtime flags to configure for Model.
+	RuntimeFlags []string `yaml:"runtime-flags"`
+}
+
+// loadRunManifest reads the project manifest from the working directory. It
+// returns nil if there is no manifest.
+func loadRunManifest() (*runManifest, error) {
+	data, err := os.ReadFile(manifestFileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", manifestFileName, err)
+	}
+	var manifest runManifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid %s: %w", manifestFileName, err)
+	}
+	if manifest.ContextSize < 0 {
+		return nil, fmt.Errorf("invalid %s: context-size must not be negative", manifestFileName)
+	}
+	return &manifest, nil
+}
+
+// configures returns true if the manifest specifies runtime options.
+func (m *runManifest) configures() bool {
+	return m.ContextSize > 0 || len(m.RuntimeFlags) > 0
+}
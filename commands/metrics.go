@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
+	"github.com/spf13/cobra"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition
+// format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+func newMetricsCmd() *cobra.Command {
+	var listen string
+	var interval time.Duration
+	c := &cobra.Command{
+		Use:   "metrics",
+		Short: "Serve Prometheus metrics about the Docker Model Runner",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive (got %s)", interval)
+			}
+			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
+				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
+			}
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("unable to listen on %s: %w", listen, err)
+			}
+			return serveMetrics(cmd, listener, desktopClient, interval)
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	c.Flags().StringVar(&listen, "listen", ":9100", "Address on which to serve metrics")
+	c.Flags().DurationVar(&interval, "interval", 15*time.Second, "How often to poll the model runner for the served metrics")
+	return c
+}
+
+// serveMetrics polls the model runner every interval and serves the latest
+// metrics on /metrics until the command's context is canceled.
+func serveMetrics(cmd *cobra.Command, listener net.Listener, client *desktop.Client, interval time.Duration) error {
+	ctx := cmd.Context()
+	var mu sync.Mutex
+	var errorCount int
+	poll := func() []byte {
+		sample := pollMetrics(client)
+		mu.Lock()
+		defer mu.Unlock()
+		if sample.err != nil {
+			errorCount++
+			cmd.PrintErrf("Failed to poll the model runner: %v\n", sample.err)
+		}
+		sample.errors = errorCount
+		return []byte(sample.render())
+	}
+
+	latest := poll()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				metrics := poll()
+				mu.Lock()
+				latest = metrics
+				mu.Unlock()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		metrics := latest
+		mu.Unlock()
+		w.Header().Set("Content-Type", metricsContentType)
+		w.Write(metrics)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	cmd.Printf("Serving metrics on http://%s/metrics\n", listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+	return nil
+}
+
+// metricsSample holds the state of the model runner at one point in time.
+type metricsSample struct {
+	time    time.Time
+	running bool
+	ps      []desktop.BackendStatus
+	df      *desktop.DiskUsage
+	// err is the first error that occurred while polling, if any.
+	err error
+	// errors is the number of polls that have failed so far.
+	errors int
+}
+
+// pollMetrics queries the model runner for the state reported as metrics.
+// Metrics that can't be queried are left out.
+func pollMetrics(client *desktop.Client) metricsSample {
+	sample := metricsSample{time: time.Now()}
+	status := client.Status()
+	sample.running = status.Running
+	if !status.Running {
+		sample.err = status.Error
+		return sample
+	}
+	ps, err := client.PS()
+	if err != nil {
+		sample.err = err
+	} else {
+		sample.ps = ps
+	}
+	df, err := client.DF()
+	if err != nil {
+		if sample.err == nil {
+			sample.err = err
+		}
+	} else {
+		sample.df = &df
+	}
+	return sample
+}
+
+// render formats the sample in the Prometheus text exposition format.
+func (s metricsSample) render() string {
+	var buf strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("docker_model_runner_up", "gauge", "Whether the model runner is running.")
+	up := 0
+	if s.running {
+		up = 1
+	}
+	fmt.Fprintf(&buf, "docker_model_runner_up %d\n", up)
+
+	metric("docker_model_runner_poll_errors_total", "counter", "Number of failed polls of the model runner.")
+	fmt.Fprintf(&buf, "docker_model_runner_poll_errors_total %d\n", s.errors)
+
+	metric("docker_model_runner_last_poll_timestamp_seconds", "gauge", "Time of the last poll of the model runner.")
+	fmt.Fprintf(&buf, "docker_model_runner_last_poll_timestamp_seconds %.3f\n", float64(s.time.UnixMilli())/1000)
+
+	if s.ps != nil {
+		loaded := make(map[string]int)
+		for _, status := range s.ps {
+			loaded[status.BackendName]++
+		}
+		metric("docker_model_runner_models_loaded", "gauge", "Number of models loaded by each backend.")
+		for _, backend := range slices.Sorted(maps.Keys(loaded)) {
+			fmt.Fprintf(&buf, "docker_model_runner_models_loaded{backend=%s} %d\n", metricsLabel(backend), loaded[backend])
+		}
+
+		metric("docker_model_runner_model_loaded", "gauge", "Models loaded by the model runner, with the mode they run in.")
+		for _, status := range s.ps {
+			fmt.Fprintf(&buf, "docker_model_runner_model_loaded{backend=%s,model=%s,mode=%s} 1\n",
+				metricsLabel(status.BackendName), metricsLabel(status.ModelName), metricsLabel(status.Mode))
+		}
+
+		metric("docker_model_runner_model_idle_seconds", "gauge", "Time since a loaded model was last used, or 0 while it's in use.")
+		for _, status := range s.ps {
+			idle := 0.0
+			if !status.LastUsed.IsZero() {
+				idle = max(s.time.Sub(status.LastUsed).Seconds(), 0)
+			}
+			fmt.Fprintf(&buf, "docker_model_runner_model_idle_seconds{backend=%s,model=%s,mode=%s} %.3f\n",
+				metricsLabel(status.BackendName), metricsLabel(status.ModelName), metricsLabel(status.Mode), idle)
+		}
+
+		metric("docker_model_runner_model_memory_bytes", "gauge", "Memory used by a loaded model, if reported by the runner.")
+		for _, status := range s.ps {
+			if status.MemoryUsage != nil {
+				fmt.Fprintf(&buf, "docker_model_runner_model_memory_bytes{backend=%s,model=%s,mode=%s} %d\n",
+					metricsLabel(status.BackendName), metricsLabel(status.ModelName), metricsLabel(status.Mode), *status.MemoryUsage)
+			}
+		}
+	}
+
+	if s.df != nil {
+		metric("docker_model_runner_models_disk_usage_bytes", "gauge", "Disk space used by models.")
+		fmt.Fprintf(&buf, "docker_model_runner_models_disk_usage_bytes %d\n", s.df.ModelsDiskUsage)
+		metric("docker_model_runner_default_backend_disk_usage_bytes", "gauge", "Disk space used by the default backend.")
+		fmt.Fprintf(&buf, "docker_model_runner_default_backend_disk_usage_bytes %d\n", s.df.DefaultBackendDiskUsage)
+	}
+	return buf.String()
+}
+
+// metricsLabel quotes a label value for the Prometheus text format.
+func metricsLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/model-cli/desktop"
+)
+
+func TestMetricsSampleRender(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	memory := uint64(1024)
+	sample := metricsSample{
+		time:    now,
+		running: true,
+		ps: []desktop.BackendStatus{
+			{BackendName: "llama.cpp", ModelName: "ai/smollm2", Mode: "completion", MemoryUsage: &memory},
+			{BackendName: "llama.cpp", ModelName: `ai/"quoted"`, Mode: "embedding", LastUsed: now.Add(-90 * time.Second)},
+		},
+		df:     &desktop.DiskUsage{ModelsDiskUsage: 2048, DefaultBackendDiskUsage: 512},
+		errors: 1,
+	}
+
+	expected := `# HELP docker_model_runner_up Whether the model runner is running.
+# TYPE docker_model_runner_up gauge
+docker_model_runner_up 1
+# HELP docker_model_runner_poll_errors_total Number of failed polls of the model runner.
+# TYPE docker_model_runner_poll_errors_total counter
+docker_model_runner_poll_errors_total 1
+# HELP docker_model_runner_last_poll_timestamp_seconds Time of the last poll of the model runner.
+# TYPE docker_model_runner_last_poll_timestamp_seconds gauge
+docker_model_runner_last_poll_timestamp_seconds 1700000000.000
+# HELP docker_model_runner_models_loaded Number of models loaded by each backend.
+# TYPE docker_model_runner_models_loaded gauge
+docker_model_runner_models_loaded{backend="llama.cpp"} 2
+# HELP docker_model_runner_model_loaded Models loaded by the model runner, with the mode they run in.
+# TYPE docker_model_runner_model_loaded gauge
+docker_model_runner_model_loaded{backend="llama.cpp",model="ai/smollm2",mode="completion"} 1
+docker_model_runner_model_loaded{backend="llama.cpp",model="ai/\"quoted\"",mode="embedding"} 1
+# HELP docker_model_runner_model_idle_seconds Time since a loaded model was last used, or 0 while it's in use.
+# TYPE docker_model_runner_model_idle_seconds gauge
+docker_model_runner_model_idle_seconds{backend="llama.cpp",model="ai/smollm2",mode="completion"} 0.000
+docker_model_runner_model_idle_seconds{backend="llama.cpp",model="ai/\"quoted\"",mode="embedding"} 90.000
+# HELP docker_model_runner_model_memory_bytes Memory used by a loaded model, if reported by the runner.
+# TYPE docker_model_runner_model_memory_bytes gauge
+docker_model_runner_model_memory_bytes{backend="llama.cpp",model="ai/smollm2",mode="completion"} 1024
+# HELP docker_model_runner_models_disk_usage_bytes Disk space used by models.
+# TYPE docker_model_runner_models_disk_usage_bytes gauge
+docker_model_runner_models_disk_usage_bytes 2048
+# HELP docker_model_runner_default_backend_disk_usage_bytes Disk space used by the default backend.
+# TYPE docker_model_runner_default_backend_disk_usage_bytes gauge
+docker_model_runner_default_backend_disk_usage_bytes 512
+`
+	if got := sample.render(); got != expected {
+		t.Errorf("render() =\n%s\nwant:\n%s", got, expected)
+	}
+
+	down := metricsSample{time: now}.render()
+	if want := "docker_model_runner_up 0\n"; !strings.Contains(down, want) {
+		t.Errorf("render() of a stopped runner = %q, want it to contain %q", down, want)
+	}
+}
@@ -37,6 +37,12 @@ func newPackagedCmd() *cobra.Command {
 					cmd.Use,
 				)
 			}
+			if err := expandPaths(&opts.ggufPath, &opts.chatTemplatePath); err != nil {
+				return err
+			}
+			if err := expandPathList(opts.licensePaths); err != nil {
+				return err
+			}
 			if opts.ggufPath == "" {
 				return fmt.Errorf(
 					"GGUF path is required.\n\n" +
@@ -96,6 +102,9 @@ func packageModel(cmd *cobra.Command, opts packageOptions) error {
 		err    error
 	)
 	if opts.push {
+		if err := ensureOnline("push " + opts.tag); err != nil {
+			return err
+		}
 		target, err = registry.NewClient(
 			registry.WithUserAgent("docker-model-cli/" + desktop.Version),
 		).NewTarget(opts.tag)
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/model-cli/desktop"
+)
+
+// parseParams parses repeated key=value arguments into request parameters.
+// Values are interpreted as booleans, integers, floating-point numbers, or
+// JSON objects and arrays where possible, and as strings otherwise.
+func parseParams(args []string) (map[string]any, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	params := make(map[string]any, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid parameter %q: expected key=value", arg)
+		}
+		parsed := parseParamValue(value)
+		if _, err := json.Marshal(parsed); err != nil {
+			return nil, fmt.Errorf("invalid parameter %q: value is not JSON-encodable: %w", arg, err)
+		}
+		params[key] = parsed
+	}
+	return params, nil
+}
+
+// parseParamValue infers the JSON type of a parameter value.
+func parseParamValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		if json.Valid([]byte(value)) {
+			return json.RawMessage(value)
+		}
+	}
+	return value
+}
+
+// addGrammarParam reads a GBNF grammar from path and adds it to params as the
+// grammar field understood by llama.cpp.
+func addGrammarParam(params map[string]any, path string) (map[string]any, error) {
+	if _, ok := params["grammar"]; ok {
+		return nil, fmt.Errorf("--grammar cannot be used with --param grammar=...")
+	}
+	grammar, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read grammar: %w", err)
+	}
+	if strings.TrimSpace(string(grammar)) == "" {
+		return nil, fmt.Errorf("grammar file %s is empty", path)
+	}
+	if params == nil {
+		params = make(map[string]any, 1)
+	}
+	params["grammar"] = string(grammar)
+	return params, nil
+}
+
+// withSampling returns params along with the sampling parameters set by
+// flags, which together describe the parameters of a request. A sampling
+// parameter can't also be set with --param.
+func withSampling(params map[string]any, sampling desktop.SamplingOptions) (map[string]any, error) {
+	data, err := json.Marshal(sampling)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return params, nil
+	}
+	merged := make(map[string]any, len(params)+len(fields))
+	for key, value := range params {
+		if _, ok := fields[key]; ok {
+			return nil, fmt.Errorf("--%s cannot be used with --param %s=...", strings.ReplaceAll(key, "_", "-"), key)
+		}
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+// checkTemperature, checkTopP, and checkMaxTokens check that the sampling
+// parameter name is within its range.
+func checkTemperature(name string, temperature float64) error {
+	if temperature < 0 {
+		return fmt.Errorf("%s must not be negative (got %g)", name, temperature)
+	}
+	return nil
+}
+
+func checkTopP(name string, topP float64) error {
+	if topP <= 0 || topP > 1 {
+		return fmt.Errorf("%s must be greater than 0 and at most 1 (got %g)", name, topP)
+	}
+	return nil
+}
+
+func checkMaxTokens(name string, maxTokens int) error {
+	if maxTokens < 1 {
+		return fmt.Errorf("%s must be at least 1 (got %d)", name, maxTokens)
+	}
+	return nil
+}
+
+// setSampling sets the sampling parameter key to value, as done by the /set
+// command of interactive chats. A value of "default" unsets the parameter.
+func setSampling(sampling *desktop.SamplingOptions, key, value string) error {
+	switch key {
+	case "temperature", "top_p":
+		target := &sampling.Temperature
+		check := checkTemperature
+		if key == "top_p" {
+			target, check = &sampling.TopP, checkTopP
+		}
+		if value == "default" {
+			*target = nil
+			return nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number (got %q)", key, value)
+		}
+		if err := check(key, f); err != nil {
+			return err
+		}
+		*target = &f
+	case "max_tokens":
+		if value == "default" {
+			sampling.MaxTokens = nil
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("max_tokens must be an integer (got %q)", value)
+		}
+		if err := checkMaxTokens(key, n); err != nil {
+			return err
+		}
+		sampling.MaxTokens = &n
+	default:
+		return fmt.Errorf("unknown parameter %q: expected temperature, top_p, or max_tokens", key)
+	}
+	return nil
+}
+
+// formatSampling describes the sampling parameters for the /show command of
+// interactive chats, one per line.
+func formatSampling(sampling desktop.SamplingOptions) string {
+	temperature, topP, maxTokens := "default", "default", "default"
+	if sampling.Temperature != nil {
+		temperature = strconv.FormatFloat(*sampling.Temperature, 'g', -1, 64)
+	}
+	if sampling.TopP != nil {
+		topP = strconv.FormatFloat(*sampling.TopP, 'g', -1, 64)
+	}
+	if sampling.MaxTokens != nil {
+		maxTokens = strconv.Itoa(*sampling.MaxTokens)
+	}
+	return fmt.Sprintf("temperature  %s\ntop_p        %s\nmax_tokens   %s\n", temperature, topP, maxTokens)
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/model-cli/desktop"
+)
+
+func TestSetSampling(t *testing.T) {
+	var sampling desktop.SamplingOptions
+	for _, set := range [][2]string{{"temperature", "0.2"}, {"top_p", "0.9"}, {"max_tokens", "64"}} {
+		if err := setSampling(&sampling, set[0], set[1]); err != nil {
+			t.Fatalf("setSampling(%q, %q) error = %v", set[0], set[1], err)
+		}
+	}
+	expected := "temperature  0.2\ntop_p        0.9\nmax_tokens   64\n"
+	if shown := formatSampling(sampling); shown != expected {
+		t.Errorf("formatSampling() = %q, want %q", shown, expected)
+	}
+
+	if err := setSampling(&sampling, "top_p", "default"); err != nil {
+		t.Fatalf("setSampling(top_p, default) error = %v", err)
+	}
+	if sampling.TopP != nil {
+		t.Errorf("top_p = %g after resetting it, want unset", *sampling.TopP)
+	}
+
+	for _, invalid := range [][2]string{
+		{"temperature", "-1"},
+		{"temperature", "hot"},
+		{"top_p", "0"},
+		{"top_p", "1.5"},
+		{"max_tokens", "0"},
+		{"max_tokens", "1.5"},
+		{"seed", "42"},
+	} {
+		if err := setSampling(&sampling, invalid[0], invalid[1]); err == nil {
+			t.Errorf("setSampling(%q, %q) succeeded, want an error", invalid[0], invalid[1])
+		}
+	}
+	if *sampling.Temperature != 0.2 || *sampling.MaxTokens != 64 {
+		t.Errorf("invalid values changed the parameters to %s", formatSampling(sampling))
+	}
+}
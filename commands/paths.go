@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandPath expands the environment variables in a path taken from a flag,
+// and then a leading ~ to the home directory, as a shell would. Paths are
+// sometimes passed programmatically, without a shell to expand them.
+//
+// Variables are written as $VAR or ${VAR}, and unset variables expand to an
+// empty string. Only ~ on its own or followed by a separator is expanded;
+// ~user is left unchanged.
+func expandPath(path string) (string, error) {
+	path = os.ExpandEnv(path)
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to expand %s: %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// expandPaths expands each of the given path flag values in place.
+func expandPaths(paths ...*string) error {
+	for _, path := range paths {
+		expanded, err := expandPath(*path)
+		if err != nil {
+			return err
+		}
+		*path = expanded
+	}
+	return nil
+}
+
+// expandPathList expands each path of a repeatable flag in place.
+func expandPathList(paths []string) error {
+	for i := range paths {
+		if err := expandPaths(&paths[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	t.Setenv("MODEL_TEST_DIR", "/tmp/prompts")
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "", expected: ""},
+		{path: "prompt.txt", expected: "prompt.txt"},
+		{path: "~", expected: home},
+		{path: "~/prompt.txt", expected: filepath.Join(home, "prompt.txt")},
+		{path: "~user/prompt.txt", expected: "~user/prompt.txt"},
+		{path: "a~/prompt.txt", expected: "a~/prompt.txt"},
+		{path: "$MODEL_TEST_DIR/prompt.txt", expected: "/tmp/prompts/prompt.txt"},
+		{path: "${MODEL_TEST_DIR}/prompt.txt", expected: "/tmp/prompts/prompt.txt"},
+		{path: "$MODEL_TEST_UNSET/prompt.txt", expected: "/prompt.txt"},
+	}
+	for _, tt := range tests {
+		got, err := expandPath(tt.path)
+		if err != nil {
+			t.Fatalf("expandPath(%q) error = %v", tt.path, err)
+		}
+		if got != tt.expected {
+			t.Errorf("expandPath(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
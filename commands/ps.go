@@ -2,67 +2,331 @@ package commands
 
 import (
 	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/docker/go-units"
 	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/commands/formatter"
 	"github.com/docker/model-cli/desktop"
+	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
+// backendModes describes the modes that a backend may report, in the order in
+// which they're listed by --help-modes.
+var backendModes = []struct {
+	name        string
+	description string
+}{
+	{"completion", "Serves chat and text completion requests (docker model run, /v1/chat/completions, /v1/completions)"},
+	{"embedding", "Serves embedding requests (/v1/embeddings)"},
+	{"unknown", "The runner reported a mode that this CLI doesn't recognize"},
+}
+
 func newPSCmd() *cobra.Command {
+	var helpModes bool
+	var sortKey string
+	var idleThreshold time.Duration
+	var filterArgs []string
+	var format string
+	var noStream bool
+	var noTrunc bool
 	c := &cobra.Command{
 		Use:   "ps",
 		Short: "List running models",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if helpModes {
+				cmd.Print(modesLegend())
+				return nil
+			}
+			if sortKey != "" && !slices.Contains([]string{"model", "backend", "last-used"}, sortKey) {
+				return fmt.Errorf("--sort must be model, backend, or last-used (got %q)", sortKey)
+			}
+			filters, err := parsePSFilters(filterArgs)
+			if err != nil {
+				return err
+			}
+			var tmpl *formatter.Template
+			if format != "" {
+				if jsonOutput {
+					return fmt.Errorf("--format flag cannot be used with --json flag")
+				}
+				if tmpl, err = formatter.ParseTemplate(format); err != nil {
+					return err
+				}
+				if noTrunc {
+					tmpl.NoTrunc()
+				}
+			}
 			ps, err := desktopClient.PS()
 			if err != nil {
 				err = handleClientError(err, "Failed to list running models")
 				return handleNotRunningError(err)
 			}
-			cmd.Print(psTable(ps))
+			ps = slices.DeleteFunc(ps, func(status desktop.BackendStatus) bool {
+				return !filters.match(status)
+			})
+			switch {
+			case sortKey == "last-used":
+				sortByLastUsed(ps)
+			case sortKey == "backend":
+				sortByBackend(ps)
+			case sortKey == "model" || noStream:
+				sortByModel(ps)
+			}
+			if jsonOutput && noStream {
+				if ps == nil {
+					// Always print a list, even if no models are running.
+					ps = []desktop.BackendStatus{}
+				}
+				output, err := formatter.ToStandardJSON(psSnapshot{Timestamp: time.Now().UTC(), Models: ps})
+				if err != nil {
+					return err
+				}
+				cmd.Print(output)
+				return nil
+			}
+			if jsonOutput {
+				// Keep the raw values reported by the runner.
+				output, err := formatter.ToStandardJSON(ps)
+				if err != nil {
+					return err
+				}
+				cmd.Print(output)
+				return nil
+			}
+			if tmpl != nil {
+				for _, status := range ps {
+					line, err := tmpl.Execute(status)
+					if err != nil {
+						return err
+					}
+					cmd.Print(line)
+				}
+				return nil
+			}
+			cmd.Print(psTable(ps, idleThreshold, noTrunc))
 			return nil
 		},
 		ValidArgsFunction: completion.NoComplete,
 	}
+	c.Flags().BoolVar(&helpModes, "help-modes", false, "Explain the values of the MODE column")
+	c.Flags().StringVar(&sortKey, "sort", "", "Sort models by the given key (model, backend, or last-used: longest idle first)")
+	c.Flags().StringArrayVarP(&filterArgs, "filter", "f", nil, "Filter output based on conditions provided (e.g. backend=llama.cpp, model=smollm2, mode=idle)")
+	c.Flags().StringVar(&format, "format", "", "Format the output using the given Go template (e.g. '{{.ModelName}} {{.Mode}}')")
+	c.Flags().BoolVar(&noStream, "no-stream", false, "Print a single snapshot in a stable order; with --json, wrap it in an object with a timestamp")
+	c.Flags().BoolVar(&noTrunc, "no-trunc", false, "Don't truncate output")
+	c.Flags().DurationVar(&idleThreshold, "idle-threshold", 3*time.Minute, "Highlight models that have been idle for longer than this duration")
 	return c
 }
 
-func psTable(ps []desktop.BackendStatus) string {
+// modesLegend explains the values of the MODE column.
+func modesLegend() string {
+	var buf strings.Builder
+	buf.WriteString("MODE describes the kind of requests a loaded model serves:\n\n")
+	for _, mode := range backendModes {
+		fmt.Fprintf(&buf, "  %-12s%s\n", mode.name, mode.description)
+	}
+	buf.WriteString("\nModes are shown in green while a request is in progress (active) and dimmed\n" +
+		"once the model is idle. Idle models are unloaded after an inactivity timeout.\n")
+	return buf.String()
+}
+
+// colorizeMode highlights a mode according to whether the backend is serving
+// a request. The runner only reports a last-used time for idle backends.
+func colorizeMode(status desktop.BackendStatus) string {
+	if status.LastUsed.IsZero() {
+		return color.GreenString(status.Mode)
+	}
+	return color.New(color.Faint).Sprint(status.Mode)
+}
+
+// psFilter is a single condition supplied via --filter.
+type psFilter struct {
+	key   string
+	value string
+}
+
+// psFilters holds the conditions supplied via --filter. All conditions must
+// hold for a backend to be shown.
+type psFilters []psFilter
+
+// parsePSFilters parses key=value filter arguments.
+func parsePSFilters(args []string) (psFilters, error) {
+	var filters psFilters
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid filter %q: expected key=value", arg)
+		}
+		switch key {
+		case "backend", "model", "mode":
+			filters = append(filters, psFilter{key: key, value: value})
+		default:
+			return nil, fmt.Errorf("invalid filter %q: unsupported key %q", arg, key)
+		}
+	}
+	return filters, nil
+}
+
+// match returns true if status satisfies all filters. The model filter
+// matches substrings of the model name. Besides the modes reported by the
+// runner, the mode filter accepts "idle" and "active".
+func (f psFilters) match(status desktop.BackendStatus) bool {
+	for _, filter := range f {
+		var ok bool
+		switch filter.key {
+		case "backend":
+			ok = status.BackendName == filter.value
+		case "model":
+			ok = strings.Contains(status.ModelName, filter.value)
+		case "mode":
+			switch filter.value {
+			case "idle":
+				ok = !status.LastUsed.IsZero()
+			case "active":
+				ok = status.LastUsed.IsZero()
+			default:
+				ok = status.Mode == filter.value
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// psSnapshot is the JSON representation of the running models printed by
+// --json --no-stream, for monitoring tools that poll ps.
+type psSnapshot struct {
+	Timestamp time.Time               `json:"timestamp"`
+	Models    []desktop.BackendStatus `json:"models"`
+}
+
+// sortByModel orders backends by model name and then by backend name, so
+// that snapshots taken at different times can be compared.
+func sortByModel(ps []desktop.BackendStatus) {
+	slices.SortStableFunc(ps, func(a, b desktop.BackendStatus) int {
+		if c := strings.Compare(a.ModelName, b.ModelName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.BackendName, b.BackendName)
+	})
+}
+
+// sortByBackend orders backends by backend name and then by model name.
+func sortByBackend(ps []desktop.BackendStatus) {
+	slices.SortStableFunc(ps, func(a, b desktop.BackendStatus) int {
+		if c := strings.Compare(a.BackendName, b.BackendName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ModelName, b.ModelName)
+	})
+}
+
+// sortByLastUsed orders backends so that those idle the longest come first.
+// Active backends, which don't report a last-used time, come last.
+func sortByLastUsed(ps []desktop.BackendStatus) {
+	slices.SortStableFunc(ps, func(a, b desktop.BackendStatus) int {
+		switch {
+		case a.LastUsed.IsZero() && b.LastUsed.IsZero():
+			return 0
+		case a.LastUsed.IsZero():
+			return 1
+		case b.LastUsed.IsZero():
+			return -1
+		}
+		return a.LastUsed.Compare(b.LastUsed)
+	})
+}
+
+// formatLastUsed humanizes a backend's last-used time, highlighting backends
+// that have been idle for longer than idleThreshold. With noTrunc, the time
+// is shown in full as RFC 3339.
+func formatLastUsed(status desktop.BackendStatus, idleThreshold time.Duration, noTrunc bool) string {
+	if status.LastUsed.IsZero() {
+		return "In use"
+	}
+	idle := time.Since(status.LastUsed)
+	lastUsed := units.HumanDuration(idle) + " ago"
+	if noTrunc {
+		lastUsed = status.LastUsed.Format(time.RFC3339)
+	}
+	if idleThreshold > 0 && idle > idleThreshold {
+		return color.YellowString(lastUsed)
+	}
+	return lastUsed
+}
+
+func psTable(ps []desktop.BackendStatus, idleThreshold time.Duration, noTrunc bool) string {
 	var buf bytes.Buffer
 	table := tablewriter.NewWriter(&buf)
 
-	table.SetHeader([]string{"MODEL NAME", "BACKEND", "MODE", "LAST USED"})
+	// Only show the optional columns if the runner reports them.
+	showContext := slices.ContainsFunc(ps, func(s desktop.BackendStatus) bool { return s.ContextSize != nil })
+	showMemory := slices.ContainsFunc(ps, func(s desktop.BackendStatus) bool { return s.MemoryUsage != nil })
+
+	header := []string{"MODEL NAME", "BACKEND", "MODE", "LAST USED"}
+	if showContext {
+		header = append(header, "CONTEXT")
+	}
+	if showMemory {
+		header = append(header, "MEMORY")
+	}
+	table.SetHeader(header)
 
 	table.SetBorder(false)
 	table.SetColumnSeparator("")
 	table.SetHeaderLine(false)
 	table.SetTablePadding("  ")
 	table.SetNoWhiteSpace(true)
+	table.SetAutoWrapText(!noTrunc)
 
-	table.SetColumnAlignment([]int{
-		tablewriter.ALIGN_LEFT, // MODEL
-		tablewriter.ALIGN_LEFT, // BACKEND
-		tablewriter.ALIGN_LEFT, // MODE
-		tablewriter.ALIGN_LEFT, // LAST USED
-	})
+	alignment := make([]int, len(header))
+	for i := range alignment {
+		alignment[i] = tablewriter.ALIGN_LEFT
+	}
+	table.SetColumnAlignment(alignment)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 
 	for _, status := range ps {
 		modelName := status.ModelName
-		if strings.HasPrefix(modelName, "sha256:") {
+		if strings.HasPrefix(modelName, "sha256:") && !noTrunc {
 			modelName = modelName[7:19]
 		}
-		table.Append([]string{
+		row := []string{
 			modelName,
 			status.BackendName,
-			status.Mode,
-			units.HumanDuration(time.Since(status.LastUsed)) + " ago",
-		})
+			colorizeMode(status),
+			formatLastUsed(status, idleThreshold, noTrunc),
+		}
+		if showContext {
+			row = append(row, optionalColumn(status.ContextSize, func(v uint64) string {
+				return strconv.FormatUint(v, 10)
+			}))
+		}
+		if showMemory {
+			row = append(row, optionalColumn(status.MemoryUsage, func(v uint64) string {
+				return formatSize(int64(v))
+			}))
+		}
+		table.Append(row)
 	}
 
 	table.Render()
 	return buf.String()
 }
+
+// optionalColumn formats an optional value, or returns "-" if it's unset.
+func optionalColumn(value *uint64, format func(uint64) string) string {
+	if value == nil {
+		return "-"
+	}
+	return format(*value)
+}
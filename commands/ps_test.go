@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/docker/model-cli/desktop"
+)
+
+func TestPSFilters(t *testing.T) {
+	active := desktop.BackendStatus{BackendName: "llama.cpp", ModelName: "ai/smollm2:latest", Mode: "completion"}
+	idle := desktop.BackendStatus{BackendName: "llama.cpp", ModelName: "ai/mxbai-embed-large", Mode: "embedding", LastUsed: time.Now()}
+
+	tests := []struct {
+		name     string
+		filters  []string
+		expected []desktop.BackendStatus
+	}{
+		{name: "no filters", expected: []desktop.BackendStatus{active, idle}},
+		{name: "backend", filters: []string{"backend=llama.cpp"}, expected: []desktop.BackendStatus{active, idle}},
+		{name: "other backend", filters: []string{"backend=vllm"}},
+		{name: "model substring", filters: []string{"model=smol"}, expected: []desktop.BackendStatus{active}},
+		{name: "idle", filters: []string{"mode=idle"}, expected: []desktop.BackendStatus{idle}},
+		{name: "active", filters: []string{"mode=active"}, expected: []desktop.BackendStatus{active}},
+		{name: "reported mode", filters: []string{"mode=embedding"}, expected: []desktop.BackendStatus{idle}},
+		{name: "AND semantics", filters: []string{"model=ai/", "mode=idle"}, expected: []desktop.BackendStatus{idle}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := parsePSFilters(tt.filters)
+			if err != nil {
+				t.Fatalf("parsePSFilters() error = %v", err)
+			}
+			var matched []desktop.BackendStatus
+			for _, status := range []desktop.BackendStatus{active, idle} {
+				if filters.match(status) {
+					matched = append(matched, status)
+				}
+			}
+			if len(matched) != len(tt.expected) {
+				t.Fatalf("matched %v, want %v", matched, tt.expected)
+			}
+			for i := range matched {
+				if matched[i].ModelName != tt.expected[i].ModelName {
+					t.Errorf("matched %v, want %v", matched, tt.expected)
+				}
+			}
+		})
+	}
+
+	for _, arg := range []string{"backend", "mode=", "name=foo"} {
+		if _, err := parsePSFilters([]string{arg}); err == nil {
+			t.Errorf("parsePSFilters(%q) should return an error", arg)
+		}
+	}
+}
+
+func TestPSSort(t *testing.T) {
+	ps := []desktop.BackendStatus{
+		{BackendName: "vllm", ModelName: "ai/qwen3"},
+		{BackendName: "llama.cpp", ModelName: "ai/smollm2"},
+		{BackendName: "llama.cpp", ModelName: "ai/gemma3"},
+	}
+	models := func() []string {
+		var names []string
+		for _, status := range ps {
+			names = append(names, status.BackendName+"/"+status.ModelName)
+		}
+		return names
+	}
+
+	sortByModel(ps)
+	if got, want := models(), []string{"llama.cpp/ai/gemma3", "vllm/ai/qwen3", "llama.cpp/ai/smollm2"}; !slices.Equal(got, want) {
+		t.Errorf("sortByModel() = %v, want %v", got, want)
+	}
+	sortByBackend(ps)
+	if got, want := models(), []string{"llama.cpp/ai/gemma3", "llama.cpp/ai/smollm2", "vllm/ai/qwen3"}; !slices.Equal(got, want) {
+		t.Errorf("sortByBackend() = %v, want %v", got, want)
+	}
+}
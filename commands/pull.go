@@ -1,17 +1,36 @@
 package commands
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/commands/formatter"
 	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/config"
+	"github.com/docker/model-cli/pkg/standalone"
+	"github.com/docker/model-cli/pkg/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 func newPullCmd() *cobra.Command {
 	var ignoreRuntimeMemoryCheck bool
+	var repair bool
+	var allTags bool
+	var tagPattern string
+	var refreshCredentials bool
+	var summaryOnly bool
+	var retries int
 
 	c := &cobra.Command{
 		Use:   "pull MODEL",
@@ -27,41 +46,486 @@ func newPullCmd() *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureOnline("pull " + args[0]); err != nil {
+				return err
+			}
 			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
 				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
 			}
-			return pullModel(cmd, desktopClient, args[0], ignoreRuntimeMemoryCheck)
+			if retries < 0 {
+				return fmt.Errorf("--retries must not be negative (got %d)", retries)
+			}
+			opts := pullOptions{
+				ignoreRuntimeMemoryCheck: ignoreRuntimeMemoryCheck,
+				retries:                  retries,
+				summaryOnly:              summaryOnly,
+				refreshCredentials:       refreshCredentials,
+			}
+			if tagPattern != "" && !allTags {
+				return fmt.Errorf("--tag-pattern can only be used with --all-tags")
+			}
+			if allTags {
+				if repair {
+					return fmt.Errorf("--repair cannot be used with --all-tags")
+				}
+				return pullAllTags(cmd, desktopClient, args[0], tagPattern, opts)
+			}
+			if repair {
+				return repairModel(cmd, desktopClient, args[0], opts)
+			}
+			return pullModel(cmd, desktopClient, args[0], opts)
 		},
-		ValidArgsFunction: completion.NoComplete,
+		ValidArgsFunction: completion.ModelNamesAndCatalog(getDesktopClient, catalogModels, 1),
 	}
 
 	c.Flags().BoolVar(&ignoreRuntimeMemoryCheck, "ignore-runtime-memory-check", false, "Do not block pull if estimated runtime memory for model exceeds system resources.")
+	c.Flags().BoolVarP(&allTags, "all-tags", "a", false, "Pull all tags of the repository")
+	c.Flags().StringVar(&tagPattern, "tag-pattern", "", "Only pull the tags that match the given glob pattern (only available with --all-tags)")
+	c.Flags().BoolVar(&refreshCredentials, "refresh-credentials", false, "If authentication fails, copy fresh credentials into the standalone model runner and retry once")
+	c.Flags().BoolVar(&summaryOnly, "summary-only", false, "Only print a summary of the pull once it is complete, without progress updates")
+	c.Flags().IntVar(&retries, "retries", desktop.DefaultPullRetries, "Retry the pull up to the given number of times, with exponential backoff, if the connection drops or the server is unavailable")
+	c.Flags().BoolVar(&repair, "repair", false, "Verify the local copy of the model and download it again if any layer is missing or corrupt")
 
 	return c
 }
 
-func pullModel(cmd *cobra.Command, desktopClient *desktop.Client, model string, ignoreRuntimeMemoryCheck bool) error {
-	var progress func(string)
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		progress = TUIProgress
-	} else {
-		progress = RawProgress
+// pullOptions are the settings of a pull.
+type pullOptions struct {
+	ignoreRuntimeMemoryCheck bool
+	// retries is the number of times a failed pull is retried.
+	retries int
+	// summaryOnly prints a summary once the pull is complete instead of
+	// progress updates.
+	summaryOnly bool
+	// refreshCredentials copies fresh credentials into a standalone runner
+	// and retries once if authentication fails.
+	refreshCredentials bool
+}
+
+// defaultPullOptions returns the settings for pulling models on demand, for
+// commands that don't have the pull command's flags.
+func defaultPullOptions(ignoreRuntimeMemoryCheck bool) pullOptions {
+	return pullOptions{
+		ignoreRuntimeMemoryCheck: ignoreRuntimeMemoryCheck,
+		retries:                  desktop.DefaultPullRetries,
 	}
-	response, progressShown, err := desktopClient.Pull(model, ignoreRuntimeMemoryCheck, progress)
+}
 
-	// Add a newline before any output (success or error) if progress was shown.
-	if progressShown {
-		cmd.Println()
+func pullModel(cmd *cobra.Command, desktopClient *desktop.Client, model string, opts pullOptions) error {
+	if err := ensureOnline("pull " + model); err != nil {
+		return err
+	}
+	printProgress, inPlace, err := progressPrinter()
+	if err != nil {
+		return err
+	}
+	newProgress := func() (func(desktop.PullProgress), func()) {
+		if opts.summaryOnly {
+			return func(desktop.PullProgress) {}, func() {}
+		}
+		if !inPlace {
+			return steppedProgress(pullProgressStep, printProgress)
+		}
+		return func(p desktop.PullProgress) {
+			printProgress(p.Message)
+		}, func() {}
+	}
+	pull := func() (string, desktop.PullSummary, error) {
+		progress, flush := newProgress()
+		printed := false
+		onProgress := func(p desktop.PullProgress) {
+			if !p.Retry {
+				progress(p)
+				printed = true
+				return
+			}
+			// Report the failed attempt on a line of its own, and start
+			// the progress of the next attempt over.
+			flush()
+			if inPlace && printed && !opts.summaryOnly {
+				pullPrintf(cmd, "\n")
+			}
+			cmd.PrintErrln(p.Message)
+			progress, flush = newProgress()
+			printed = false
+		}
+		response, progressShown, summary, err := desktopClient.PullWithSummary(cmd.Context(), model, opts.ignoreRuntimeMemoryCheck, opts.retries, onProgress)
+		flush()
+		// Add a newline before any output (success or error) if progress was shown.
+		if progressShown && !opts.summaryOnly {
+			pullPrintf(cmd, "\n")
+		}
+		return response, summary, err
+	}
+	start := time.Now()
+	response, summary, err := pull()
+
+	// Stale credentials may have been copied into a standalone runner, in
+	// which case copying them again and retrying can help.
+	if opts.refreshCredentials && errors.Is(err, desktop.ErrUnauthorized) {
+		cmd.PrintErrln("Authentication failed, copying fresh credentials into the model runner and retrying")
+		if refreshErr := refreshRunnerCredentials(cmd); refreshErr != nil {
+			return handleAuthError(err, model, refreshErr)
+		}
+		start = time.Now()
+		response, summary, err = pull()
 	}
 
 	if err != nil {
+		if errors.Is(err, desktop.ErrUnauthorized) {
+			return handleAuthError(err, model, nil)
+		}
 		return handleNotRunningError(handleClientError(err, "Failed to pull model"))
 	}
 
+	elapsed := time.Since(start)
+	if jsonOutput {
+		return printPullSummaryJSON(cmd, newPullSummaryJSON(model, response, summary, elapsed))
+	}
+	if opts.summaryOnly {
+		cmd.Println(pullSummary(model, response, summary, elapsed))
+		return nil
+	}
 	cmd.Println(response)
 	return nil
 }
 
+// pullSummaryJSON is the JSON representation of a completed pull. Layers
+// that were already present locally aren't reported by the model runner, so
+// only the downloaded ones are counted.
+type pullSummaryJSON struct {
+	Model            string `json:"model"`
+	Status           string `json:"status"`
+	LayersDownloaded int    `json:"layers_downloaded"`
+	BytesTotal       uint64 `json:"bytes_total"`
+	BytesDownloaded  uint64 `json:"bytes_downloaded"`
+	BytesReused      uint64 `json:"bytes_reused"`
+	DurationMS       int64  `json:"duration_ms"`
+}
+
+func newPullSummaryJSON(model, status string, summary desktop.PullSummary, elapsed time.Duration) pullSummaryJSON {
+	s := pullSummaryJSON{
+		Model:            model,
+		Status:           status,
+		LayersDownloaded: summary.Layers,
+		BytesTotal:       summary.Total,
+		BytesDownloaded:  summary.Downloaded,
+		DurationMS:       elapsed.Milliseconds(),
+	}
+	if s.BytesTotal > s.BytesDownloaded {
+		s.BytesReused = s.BytesTotal - s.BytesDownloaded
+	}
+	return s
+}
+
+func printPullSummaryJSON(cmd *cobra.Command, summary pullSummaryJSON) error {
+	output, err := formatter.ToStandardJSON(summary)
+	if err != nil {
+		return err
+	}
+	cmd.Print(output)
+	return nil
+}
+
+// pullPrintf prints the text output of pull, which goes to stderr with --json
+// to keep the standard output free for the JSON summaries.
+func pullPrintf(cmd *cobra.Command, format string, a ...any) {
+	if jsonOutput {
+		cmd.PrintErrf(format, a...)
+		return
+	}
+	cmd.Printf(format, a...)
+}
+
+// pullSummary describes a completed pull on a single line.
+func pullSummary(model, status string, summary desktop.PullSummary, elapsed time.Duration) string {
+	elapsed = elapsed.Round(100 * time.Millisecond)
+	if summary.Downloaded == 0 || elapsed <= 0 {
+		return fmt.Sprintf("%s: %s in %s, nothing downloaded", model, status, elapsed)
+	}
+	speed := float64(summary.Downloaded) / elapsed.Seconds()
+	return fmt.Sprintf("%s: %s in %s, downloaded %s of %s (%s/s)", model, status, elapsed,
+		formatSize(int64(summary.Downloaded)), formatSize(int64(summary.Total)), formatSize(int64(speed)))
+}
+
+// handleAuthError explains how to fix a registry authentication failure that
+// occurred while pulling model. refreshErr is the error that prevented
+// refreshing the runner's credentials, if any.
+func handleAuthError(err error, model string, refreshErr error) error {
+	login := "docker login"
+	if ref, parseErr := name.ParseReference(model); parseErr == nil && ref.Context().RegistryStr() != name.DefaultRegistry {
+		login += " " + ref.Context().RegistryStr()
+	}
+	hint := fmt.Sprintf("Authentication with the registry failed. Run '%s' to renew your credentials and try again.", login)
+	if refreshErr != nil {
+		hint += fmt.Sprintf("\nUnable to refresh the model runner's credentials: %v", refreshErr)
+	} else if kind := modelRunner.EngineKind(); kind == types.ModelRunnerEngineKindMoby {
+		hint += "\nThe standalone model runner keeps a copy of your credentials; use --refresh-credentials to update it."
+	}
+	return fmt.Errorf("%w\n%s", handleClientError(err, "Failed to pull model"), hint)
+}
+
+// refreshRunnerCredentials copies the host's registry credentials into the
+// standalone model runner container again.
+func refreshRunnerCredentials(cmd *cobra.Command) error {
+	engineKind := modelRunner.EngineKind()
+	if engineKind != types.ModelRunnerEngineKindMoby {
+		return errors.New("only standalone model runners on Docker Engine keep a copy of the credentials")
+	}
+	dockerClient, err := desktop.DockerClientForContext(dockerCLI, dockerCLI.CurrentContext())
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	ctrID, _, _, err := standalone.FindControllerContainer(cmd.Context(), dockerClient, runnerName)
+	if err != nil {
+		return fmt.Errorf("unable to identify Model Runner container: %w", err)
+	} else if ctrID == "" {
+		return errors.New("unable to identify Model Runner container")
+	}
+	return standalone.RefreshControllerDockerConfig(cmd.Context(), dockerClient, ctrID, engineKind, registriesConfigPath())
+}
+
+// pullAllTags pulls every tag of a repository that matches pattern, or all of
+// them if pattern is empty. Failing to pull one tag doesn't prevent pulling
+// the others.
+func pullAllTags(cmd *cobra.Command, desktopClient *desktop.Client, repository, pattern string, opts pullOptions) error {
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --tag-pattern: %w", err)
+		}
+	}
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return fmt.Errorf("invalid repository %q (tags and digests can't be used with --all-tags): %w", repository, err)
+	}
+	keychain, err := registryKeychain()
+	if err != nil {
+		return err
+	}
+	tags, err := remote.List(repo, remote.WithContext(cmd.Context()), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return fmt.Errorf("failed to list tags of %s: %w", repository, err)
+	}
+	var failed []string
+	pulled := 0
+	for _, tag := range tags {
+		if pattern != "" {
+			if matched, _ := path.Match(pattern, tag); !matched {
+				continue
+			}
+		}
+		model := repository + ":" + tag
+		pullPrintf(cmd, "Pulling %s\n", model)
+		if err := pullModel(cmd, desktopClient, model, opts); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			cmd.PrintErrf("Failed to pull %s: %v\n", model, err)
+			failed = append(failed, tag)
+			continue
+		}
+		pulled++
+	}
+	if pulled+len(failed) == 0 {
+		if pattern != "" {
+			return fmt.Errorf("no tags of %s match %q", repository, pattern)
+		}
+		return fmt.Errorf("repository %s has no tags", repository)
+	}
+	pullPrintf(cmd, "Pulled %d of %d tag(s) of %s\n", pulled, pulled+len(failed), repository)
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to pull %d tag(s) of %s: %s", len(failed), repository, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// repairModel verifies the local copy of a model and, if it is incomplete or
+// corrupt, removes it along with all of its tags, pulls it again and restores
+// the tags. Layers that the model shares with other models stay in the store,
+// so a corrupt shared layer can only be repaired by removing those models too.
+func repairModel(cmd *cobra.Command, desktopClient *desktop.Client, model string, opts pullOptions) error {
+	layers, err := verifyStoredModel(cmd.Context(), desktopClient, model)
+	if err != nil {
+		if errors.Is(err, desktop.ErrNotFound) {
+			// Nothing to repair; a regular pull restores the model.
+			return pullModel(cmd, desktopClient, model, opts)
+		}
+		if errors.Is(err, errVerifyUnsupported) {
+			return err
+		}
+		return handleNotRunningError(handleClientError(err, "Failed to verify model "+model))
+	}
+	corrupt := corruptLayers(layers)
+	if len(corrupt) == 0 {
+		pullPrintf(cmd, "Model %s is intact (%d layer(s) verified)\n", model, len(layers))
+		return nil
+	}
+	stored, err := desktopClient.Inspect(model, false)
+	if err != nil {
+		return handleNotRunningError(handleClientError(err, "Failed to get model "+model))
+	}
+	pullPrintf(cmd, "Model %s has %d corrupt layer(s), repairing\n", model, len(corrupt))
+	// Removing the model by ID removes every tag, so that no tag keeps the
+	// corrupt content in the store.
+	if _, err := desktopClient.Remove([]string{stored.ID}, true); err != nil {
+		return handleNotRunningError(handleClientError(err, "Failed to remove corrupt model "+model))
+	}
+	if err := pullModel(cmd, desktopClient, model, opts); err != nil {
+		return err
+	}
+	return restoreTags(cmd, desktopClient, model, stored.Tags)
+}
+
+// restoreTags tags model with each of tags that pulling it didn't restore.
+func restoreTags(cmd *cobra.Command, desktopClient *desktop.Client, model string, tags []string) error {
+	pulled, err := desktopClient.Inspect(model, false)
+	if err != nil {
+		return handleNotRunningError(handleClientError(err, "Failed to get model "+model))
+	}
+	var failed []string
+	for _, t := range tags {
+		if slices.Contains(pulled.Tags, t) {
+			continue
+		}
+		tag, err := name.NewTag(t)
+		if err == nil {
+			err = desktopClient.Tag(pulled.ID, parseRepo(tag), tag.TagStr())
+		}
+		if err != nil {
+			cmd.PrintErrf("Failed to restore tag %q: %v\n", t, err)
+			failed = append(failed, t)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("model %s was repaired, but these tags couldn't be restored: %s", model, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// isLoadFailure reports whether err, returned by a generation request, comes
+// from the runner failing to load the model, which is what an incomplete or
+// corrupt model leads to. Other failures, such as a missing backend or a lack
+// of memory, aren't worth verifying the model for.
+func isLoadFailure(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "unable to load runner") || strings.Contains(msg, "model unavailable")
+}
+
+// offerRepair checks whether a model that failed to load, with err, is
+// corrupt and, if so, offers to repair it. It returns true if the model was
+// repaired.
+func offerRepair(cmd *cobra.Command, desktopClient *desktop.Client, model string, err error) bool {
+	if !isLoadFailure(err) {
+		return false
+	}
+	layers, err := verifyStoredModel(cmd.Context(), desktopClient, model)
+	if err != nil || len(corruptLayers(layers)) == 0 {
+		// Either the model is intact or we can't tell, so the original error
+		// stands on its own.
+		return false
+	}
+	cmd.PrintErrf("Model %s appears to be incomplete or corrupt (%d of %d layer(s) failed verification).\n",
+		model, len(corruptLayers(layers)), len(layers))
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		cmd.PrintErrf("Run 'docker model pull --repair %s' to repair it.\n", model)
+		return false
+	}
+	cmd.PrintErr("Repair it now? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return false
+	}
+	if err := repairModel(cmd, desktopClient, model, defaultPullOptions(false)); err != nil {
+		cmd.PrintErrln(err)
+		return false
+	}
+	return true
+}
+
+// confirmPull asks whether to pull model, which isn't available locally,
+// along with its size if the registry reported it in remote, which may be
+// nil. It doesn't ask if the standard input isn't a terminal.
+func confirmPull(cmd *cobra.Command, model string, remote *desktop.Model) bool {
+	if !isatty.IsTerminal(os.Stdin.Fd()) || isOffline() {
+		return true
+	}
+	question := fmt.Sprintf("Model %s isn't available locally, pull now? [Y/n] ", model)
+	if remote != nil && remote.Config.Size != "" {
+		question = fmt.Sprintf("Model %s is %s, pull now? [Y/n] ", model, remote.Config.Size)
+	}
+	cmd.PrintErr(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// progressFunc returns the progress printer selected by the progress-style
+// setting. By default, in-place updates are used only when writing to a
+// terminal.
+func progressFunc() (func(string), error) {
+	printProgress, _, err := progressPrinter()
+	return printProgress, err
+}
+
+// progressPrinter is like progressFunc but also reports whether the printer
+// updates the progress in place.
+func progressPrinter() (func(string), bool, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, false, err
+	}
+	if jsonOutput {
+		// Keep the standard output free for JSON.
+		return func(message string) {
+			fmt.Fprintln(os.Stderr, message)
+		}, false, nil
+	}
+	switch cfg.ProgressStyle {
+	case config.ProgressStyleTTY:
+		return TUIProgress, true, nil
+	case config.ProgressStylePlain:
+		return RawProgress, false, nil
+	}
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		return TUIProgress, true, nil
+	}
+	return RawProgress, false, nil
+}
+
+// pullProgressStep is the number of percent by which a pull must advance
+// before its progress is printed again on a new line.
+const pullProgressStep = 5
+
+// steppedProgress returns a progress function for pulls that prints an update
+// only each time the download has advanced by another step percent, which
+// keeps the progress readable when every update is printed on its own line.
+// flush prints the last update if it was skipped, and must be called once the
+// pull is complete.
+func steppedProgress(step int, printProgress func(string)) (progress func(desktop.PullProgress), flush func()) {
+	printed := -1
+	pending := ""
+	progress = func(p desktop.PullProgress) {
+		percent := 100
+		if p.Total > 0 {
+			percent = int(min(p.Current*100/p.Total, 100))
+		}
+		if percent -= percent % step; percent <= printed {
+			pending = p.Message
+			return
+		}
+		printed = percent
+		pending = ""
+		printProgress(p.Message)
+	}
+	flush = func() {
+		if pending != "" {
+			printProgress(pending)
+			pending = ""
+		}
+	}
+	return progress, flush
+}
+
 func TUIProgress(message string) {
 	fmt.Print("\r\033[K", message)
 }
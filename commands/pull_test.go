@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/docker/model-cli/desktop"
+)
+
+func TestSteppedProgress(t *testing.T) {
+	var lines []string
+	progress, flush := steppedProgress(25, func(s string) { lines = append(lines, s) })
+	for _, current := range []uint64{0, 10, 30, 40, 60, 70, 100} {
+		progress(desktop.PullProgress{Message: fmt.Sprintf("%d of 100", current), Current: current, Total: 100})
+	}
+	progress(desktop.PullProgress{Message: "100 of 100 (20 already present locally)", Current: 100, Total: 100})
+	flush()
+
+	expected := []string{
+		"0 of 100",
+		"30 of 100",
+		"60 of 100",
+		"100 of 100",
+		"100 of 100 (20 already present locally)",
+	}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("steppedProgress() printed %q, want %q", lines, expected)
+	}
+}
+
+func TestIsLoadFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("error response: status=500 body=unable to load runner: llama.cpp terminated"), true},
+		{fmt.Errorf("error response: status=500 body=model unavailable"), true},
+		{fmt.Errorf("error response: status=404 body=backend not found"), false},
+		{fmt.Errorf("error response: status=507 body=insufficient memory"), false},
+	}
+	for _, tt := range tests {
+		if got := isLoadFailure(tt.err); got != tt.want {
+			t.Errorf("isLoadFailure(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -23,18 +23,28 @@ func newPushCmd() *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := ensureOnline("push " + args[0]); err != nil {
+				return err
+			}
 			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
 				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
 			}
 			return pushModel(cmd, desktopClient, args[0])
 		},
-		ValidArgsFunction: completion.NoComplete,
+		ValidArgsFunction: completion.ModelNames(getDesktopClient, 1),
 	}
 	return c
 }
 
 func pushModel(cmd *cobra.Command, desktopClient *desktop.Client, model string) error {
-	response, progressShown, err := desktopClient.Push(model, TUIProgress)
+	if err := ensureOnline("push " + model); err != nil {
+		return err
+	}
+	progress, err := progressFunc()
+	if err != nil {
+		return err
+	}
+	response, progressShown, err := desktopClient.Push(cmd.Context(), model, progress)
 
 	// Add a newline before any output (success or error) if progress was shown.
 	if progressShown {
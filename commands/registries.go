@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	cliconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// registriesConfig is set by the global --registries-config flag.
+var registriesConfig string
+
+// registriesConfigPath returns the Docker config file that holds the registry
+// credentials for model operations, either from the --registries-config flag
+// or from the MODEL_REGISTRIES_CONFIG environment variable. It returns "" if
+// the Docker CLI's configuration should be used.
+func registriesConfigPath() string {
+	if registriesConfig != "" {
+		return registriesConfig
+	}
+	return os.Getenv("MODEL_REGISTRIES_CONFIG")
+}
+
+// registryKeychain returns the keychain used to access registries directly.
+func registryKeychain() (authn.Keychain, error) {
+	path := registriesConfigPath()
+	if path == "" {
+		return authn.DefaultKeychain, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to use registries config: %w", err)
+	}
+	defer f.Close()
+	cf, err := cliconfig.LoadFromReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to use registries config %s: %w", path, err)
+	}
+	cf.Filename = path
+	return configFileKeychain{cf}, nil
+}
+
+// configFileKeychain resolves registry credentials from a Docker config file,
+// including any credential helpers it configures.
+type configFileKeychain struct {
+	cf *configfile.ConfigFile
+}
+
+func (k configFileKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	registry := target.RegistryStr()
+	if registry == name.DefaultRegistry {
+		// Docker Hub credentials are stored under its legacy address.
+		registry = "https://index.docker.io/v1/"
+	}
+	cfg, err := k.cf.GetAuthConfig(registry)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Username == "" && cfg.Password == "" && cfg.Auth == "" &&
+		cfg.IdentityToken == "" && cfg.RegistryToken == "" {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		Auth:          cfg.Auth,
+		IdentityToken: cfg.IdentityToken,
+		RegistryToken: cfg.RegistryToken,
+	}), nil
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/cobra"
+)
+
+func newRenameCmd() *cobra.Command {
+	var force bool
+
+	c := &cobra.Command{
+		Use:   "rename SOURCE TARGET",
+		Short: "Rename a model tag",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf(
+					"'docker model rename' requires 2 arguments.\n\n" +
+						"Usage:  docker model rename SOURCE TARGET\n\n" +
+						"See 'docker model rename --help' for more information",
+				)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
+				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
+			}
+			return renameModel(cmd, desktopClient, args[0], args[1], force)
+		},
+		ValidArgsFunction: completion.ModelNames(getDesktopClient, 1),
+	}
+
+	c.Flags().BoolVarP(&force, "force", "f", false, "Overwrite the target if it already exists")
+	return c
+}
+
+// renameModel tags source as target and then removes the source tag.
+func renameModel(cmd *cobra.Command, desktopClient *desktop.Client, source, target string, force bool) error {
+	sourceTag, err := name.NewTag(source)
+	if err != nil {
+		return fmt.Errorf("invalid source: %w", err)
+	}
+	targetTag, err := name.NewTag(target)
+	if err != nil {
+		return fmt.Errorf("invalid tag: %w", err)
+	}
+	if sourceTag.Name() == targetTag.Name() {
+		return fmt.Errorf("source and target refer to the same tag: %s", source)
+	}
+
+	model, err := desktopClient.Inspect(source, false)
+	if err != nil {
+		err = handleClientError(err, "Failed to get model "+source)
+		return handleNotRunningError(err)
+	}
+	if model.ID == source || strings.HasPrefix(strings.TrimPrefix(model.ID, "sha256:"), source) {
+		return fmt.Errorf("source must be a tag, not a model ID: %s", source)
+	}
+
+	if existing, err := desktopClient.Inspect(target, false); err == nil {
+		if !force {
+			return fmt.Errorf("target %s already exists (use --force to overwrite it)", target)
+		}
+		if existing.ID != model.ID {
+			cmd.PrintErrf("Overwriting %s, which referred to %s\n", target, existing.ID)
+		}
+	} else if !errors.Is(err, desktop.ErrNotFound) {
+		err = handleClientError(err, "Failed to get model "+target)
+		return handleNotRunningError(err)
+	}
+
+	if err := desktopClient.Tag(source, parseRepo(targetTag), targetTag.TagStr()); err != nil {
+		return fmt.Errorf("failed to tag model: %w", err)
+	}
+	// The model now has at least two tags, so removing the source only untags
+	// it.
+	if _, err := desktopClient.Remove([]string{source}, false); err != nil {
+		err = handleClientError(err, fmt.Sprintf("Tagged %s but failed to remove %s", target, source))
+		return handleNotRunningError(err)
+	}
+	cmd.Printf("Renamed %s to %s\n", source, target)
+	return nil
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/model-cli/pkg/config"
+)
+
+// defaultResponseCacheTTL is how long cached responses are kept if neither
+// --cache-ttl nor the response-cache-ttl setting is specified.
+const defaultResponseCacheTTL = 24 * time.Hour
+
+// responseCache stores the responses to single prompts on disk, so that
+// identical requests can be answered without querying the model again.
+type responseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// cachedResponse is the on-disk representation of a cached response.
+type cachedResponse struct {
+	Created  time.Time `json:"created"`
+	Response string    `json:"response"`
+}
+
+// newResponseCache returns a cache stored in the Model CLI state directory.
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{dir: filepath.Join(config.Dir(), "cache", "responses"), ttl: ttl}
+}
+
+// responseCacheKey identifies a request by everything that affects its
+// response. model should be the model ID when it is known, so that pulling a
+// new version of a tag doesn't return stale responses.
+func responseCacheKey(model, backend, system, prompt string, raw bool, params map[string]any, think *bool) (string, error) {
+	// Map keys are marshaled in sorted order, so equal parameters always
+	// produce the same key.
+	data, err := json.Marshal(struct {
+		Model   string         `json:"model"`
+		Backend string         `json:"backend"`
+		Raw     bool           `json:"raw"`
+		Params  map[string]any `json:"params"`
+		Think   *bool          `json:"think,omitempty"`
+		System  string         `json:"system,omitempty"`
+		Prompt  string         `json:"prompt"`
+	}{model, backend, raw, params, think, system, prompt})
+	if err != nil {
+		return "", fmt.Errorf("unable to compute response cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// resolveResponseCache returns the response cache selected by the --cache,
+// --no-cache, and --cache-ttl flags and the response-cache-ttl setting, or nil
+// if responses shouldn't be cached.
+func resolveResponseCache(enable, disable bool, ttl time.Duration, ttlSet bool) (*responseCache, error) {
+	if disable {
+		return nil, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if !enable && !ttlSet && cfg.ResponseCacheTTL == "" {
+		return nil, nil
+	}
+	if !ttlSet {
+		ttl = defaultResponseCacheTTL
+		if cfg.ResponseCacheTTL != "" {
+			if ttl, err = time.ParseDuration(cfg.ResponseCacheTTL); err != nil {
+				return nil, fmt.Errorf("invalid %s setting: %w", config.KeyResponseCacheTTL, err)
+			}
+		}
+	}
+	return newResponseCache(ttl), nil
+}
+
+func (c *responseCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// get returns the cached response for key, if there is one that hasn't
+// expired. Expired entries are removed.
+func (c *responseCache) get(key string, now time.Time) (string, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || now.Sub(entry.Created) > c.ttl {
+		_ = os.Remove(c.path(key))
+		return "", false
+	}
+	return entry.Response, true
+}
+
+// put stores response for key. The entry is written to a temporary file first
+// so that concurrent runs never read a partial entry.
+func (c *responseCache) put(key, response string, now time.Time) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("unable to create response cache directory: %w", err)
+	}
+	data, err := json.Marshal(cachedResponse{Created: now, Response: response})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to write response cache entry: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write response cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	key := func(model, backend, prompt string, raw bool, params map[string]any) string {
+		t.Helper()
+		k, err := responseCacheKey(model, backend, "", prompt, raw, params, nil)
+		if err != nil {
+			t.Fatalf("responseCacheKey() error = %v", err)
+		}
+		return k
+	}
+	base := key("sha256:abc", "llama.cpp", "hi", false, map[string]any{"temperature": 0, "seed": 1})
+	if same := key("sha256:abc", "llama.cpp", "hi", false, map[string]any{"seed": 1, "temperature": 0}); same != base {
+		t.Errorf("keys differ for equal parameters: %s != %s", same, base)
+	}
+	for name, other := range map[string]string{
+		"model":   key("sha256:def", "llama.cpp", "hi", false, map[string]any{"temperature": 0, "seed": 1}),
+		"backend": key("sha256:abc", "openai", "hi", false, map[string]any{"temperature": 0, "seed": 1}),
+		"prompt":  key("sha256:abc", "llama.cpp", "hello", false, map[string]any{"temperature": 0, "seed": 1}),
+		"raw":     key("sha256:abc", "llama.cpp", "hi", true, map[string]any{"temperature": 0, "seed": 1}),
+		"params":  key("sha256:abc", "llama.cpp", "hi", false, map[string]any{"temperature": 0, "seed": 2}),
+	} {
+		if other == base {
+			t.Errorf("changing the %s doesn't change the key", name)
+		}
+	}
+	if other, err := responseCacheKey("sha256:abc", "llama.cpp", "Be brief.", "hi", false, map[string]any{"temperature": 0, "seed": 1}, nil); err != nil || other == base {
+		t.Errorf("changing the system prompt doesn't change the key")
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	cache := &responseCache{dir: t.TempDir(), ttl: time.Hour}
+	now := time.Now()
+	if _, ok := cache.get("key", now); ok {
+		t.Fatal("get() found an entry in an empty cache")
+	}
+	if err := cache.put("key", "response", now); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+	if response, ok := cache.get("key", now.Add(time.Minute)); !ok || response != "response" {
+		t.Errorf("get() = %q, %v, want %q, true", response, ok, "response")
+	}
+	if _, ok := cache.get("key", now.Add(2*time.Hour)); ok {
+		t.Error("get() returned an expired entry")
+	}
+	if _, ok := cache.get("key", now); ok {
+		t.Error("get() returned an entry that should have been removed on expiry")
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 
 	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
 	"github.com/spf13/cobra"
 )
 
 func newRemoveCmd() *cobra.Command {
 	var force bool
+	var parallel int
 
 	c := &cobra.Command{
 		Use:   "rm [MODEL...]",
@@ -24,15 +26,23 @@ func newRemoveCmd() *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if parallel < 1 {
+				return fmt.Errorf("--parallel must be at least 1 (got %d)", parallel)
+			}
 			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
 				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
 			}
-			response, err := desktopClient.Remove(args, force)
+			response, err := desktopClient.RemoveParallel(args, force, parallel)
 			if response != "" {
 				cmd.Print(response)
 			}
 			if err != nil {
-				err = handleClientError(err, "Failed to remove model")
+				// The errors of all the models that failed are reported together.
+				if errs, ok := err.(interface{ Unwrap() []error }); ok && len(errs.Unwrap()) > 1 {
+					err = handleClientError(err, fmt.Sprintf("Failed to remove %d of %d models", len(errs.Unwrap()), len(args)))
+				} else {
+					err = handleClientError(err, "Failed to remove model")
+				}
 				return handleNotRunningError(err)
 			}
 			return nil
@@ -41,5 +51,6 @@ func newRemoveCmd() *cobra.Command {
 	}
 
 	c.Flags().BoolVarP(&force, "force", "f", false, "Forcefully remove the model")
+	c.Flags().IntVar(&parallel, "parallel", desktop.DefaultRemoveParallel, "Number of models to remove concurrently")
 	return c
 }
@@ -1,12 +1,20 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/docker/cli/cli-plugins/plugin"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/debug"
 	"github.com/docker/cli/cli/flags"
 	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/standalone"
 	"github.com/spf13/cobra"
 )
 
@@ -39,6 +47,60 @@ func getDesktopClient() *desktop.Client {
 	return desktopClient
 }
 
+// offline is set by the global --offline flag.
+var offline bool
+
+// treatDesktopAsMoby is set by the global --treat-desktop-as-moby flag.
+var treatDesktopAsMoby bool
+
+// jsonOutput is set by the global --json flag. Commands that produce data
+// switch their output to JSON when it is set.
+var jsonOutput bool
+
+// errOffline is returned by operations that require network access when
+// offline mode is enabled.
+var errOffline = errors.New("offline mode enabled")
+
+// isOffline returns true if offline mode is enabled, either via the --offline
+// flag or the MODEL_CLI_OFFLINE environment variable.
+func isOffline() bool {
+	if offline {
+		return true
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("MODEL_CLI_OFFLINE"))
+	return enabled
+}
+
+// ensureOnline returns an error describing the specified operation if offline
+// mode is enabled.
+func ensureOnline(operation string) error {
+	if isOffline() {
+		return fmt.Errorf("cannot %s: %w", operation, errOffline)
+	}
+	return nil
+}
+
+// cancelOnSignal cancels the context of cmd when the process receives SIGINT
+// or SIGTERM, so that long-running operations can clean up and return. A
+// second signal exits immediately.
+func cancelOnSignal(cmd *cobra.Command) {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	cmd.SetContext(ctx)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// The resulting error is reported as a cancellation by main, so
+		// don't print it along with the usage.
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		cancel()
+		<-signals
+		fmt.Fprintln(cmd.ErrOrStderr(), "\nForced exit")
+		os.Exit(130)
+	}()
+}
+
 func NewRootCmd(cli *command.DockerCli) *cobra.Command {
 	// If we're running in standalone mode, then we're responsible for
 	// initializing the CLI. In this case, we'll need to initialize the client
@@ -56,6 +118,9 @@ func NewRootCmd(cli *command.DockerCli) *cobra.Command {
 			// Finalize initialization of the CLI.
 			if plugin.RunningStandalone() {
 				globalOptions.SetDefaultOptions(rootCmd.Flags())
+				if globalOptions.Debug {
+					debug.Enable()
+				}
 				if err := cli.Initialize(globalOptions); err != nil {
 					return fmt.Errorf("unable to configure CLI: %w", err)
 				}
@@ -64,14 +129,32 @@ func NewRootCmd(cli *command.DockerCli) *cobra.Command {
 			}
 			dockerCLI = cli
 
+			// Abort in-flight requests on SIGINT or SIGTERM.
+			cancelOnSignal(cmd)
+
+			if err := expandPaths(&registriesConfig); err != nil {
+				return err
+			}
+
+			// The flag is a per-invocation equivalent of the environment
+			// variable, which is also consulted outside of this package.
+			if treatDesktopAsMoby {
+				if err := os.Setenv(standalone.TreatDesktopAsMobyEnv, "1"); err != nil {
+					return err
+				}
+			}
+			if err := checkRunnerPreference(); err != nil {
+				return err
+			}
+
 			// Detect the model runner context and create a client for it.
 			var err error
-			modelRunner, err = desktop.DetectContext(cmd.Context(), dockerCLI)
+			modelRunner, err = detectModelRunner(cmd)
 			if err != nil {
 				return fmt.Errorf("unable to detect model runner context: %w", err)
 			}
 			desktopClient = desktop.New(modelRunner)
-			return nil
+			return targetRunner(cmd)
 		},
 		// If running standalone, then we'll register global Docker flags as
 		// top-level flags on the root command, so we'll have to set
@@ -90,25 +173,50 @@ func NewRootCmd(cli *command.DockerCli) *cobra.Command {
 		globalOptions.InstallFlags(rootCmd.Flags())
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Format output as JSON where supported")
+	rootCmd.PersistentFlags().StringVar(&registriesConfig, "registries-config", "",
+		"Docker config file with the registry credentials to use for model operations (also MODEL_REGISTRIES_CONFIG)")
+	rootCmd.PersistentFlags().StringVar(&runnerName, "runner", "",
+		"Name of the standalone Docker Model Runner container to use, when several are installed")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false,
+		"Disable registry and other network operations beyond the local model runner (also MODEL_CLI_OFFLINE=1)")
+	rootCmd.PersistentFlags().StringVar(&preferredRunner, "prefer", "",
+		"Model runner to use when both are available: desktop (Docker Desktop's built-in runner, the default) or standalone")
+	rootCmd.PersistentFlags().BoolVar(&treatDesktopAsMoby, "treat-desktop-as-moby", false,
+		"Use a standalone model runner even with Docker Desktop, for testing (also "+standalone.TreatDesktopAsMobyEnv+"=1)")
+
 	// Add subcommands.
 	rootCmd.AddCommand(
 		newVersionCmd(),
 		newStatusCmd(),
+		newEngineCmd(),
 		newPullCmd(),
 		newPushCmd(),
+		newLoginCmd(),
+		newLogoutCmd(),
 		newPackagedCmd(),
+		newSaveCmd(),
+		newLoadCmd(),
 		newListCmd(),
 		newLogsCmd(),
 		newRunCmd(),
 		newRemoveCmd(),
 		newInspectCmd(),
+		newScanCmd(),
 		newComposeCmd(),
 		newTagCmd(),
+		newRenameCmd(),
 		newInstallRunner(),
 		newUninstallRunner(),
+		newRunnerConfigCmd(),
+		newWaitCmd(),
 		newConfigureCmd(),
+		newConfigCmd(),
+		newSessionCmd(),
 		newPSCmd(),
 		newDFCmd(),
+		newMetricsCmd(),
+		newGCCmd(),
 		newUnloadCmd(),
 		newRequestsCmd(),
 	)
@@ -2,15 +2,23 @@ package commands
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/docker/model-cli/commands/completion"
 	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/config"
+	"github.com/docker/model-runner/pkg/inference/scheduling"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -86,6 +94,26 @@ var (
 	lastWidth        int
 )
 
+// readInteractiveInput is like readMultilineInput, but returns the context's
+// error if ctx is cancelled while waiting for input.
+func readInteractiveInput(ctx context.Context, cmd *cobra.Command, scanner *bufio.Scanner) (string, error) {
+	type result struct {
+		input string
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		input, err := readMultilineInput(cmd, scanner)
+		done <- result{input, err}
+	}()
+	select {
+	case r := <-done:
+		return r.input, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
 // StreamingMarkdownBuffer handles partial content and renders complete markdown blocks
 type StreamingMarkdownBuffer struct {
 	buffer       strings.Builder
@@ -261,23 +289,66 @@ func renderMarkdown(content string) (string, error) {
 	return rendered, nil
 }
 
+// chatPrinter returns a callback for Client.Chat that prints the response
+// content with printContent. Reasoning content is printed in italics under a
+// "Thinking:" header, and the token usage, if reported, ends the output.
+func chatPrinter(printContent func(string), colorUsage bool) func(desktop.ChatDelta) {
+	const (
+		printedNone = iota
+		printedReasoning
+		printedContent
+	)
+	state := printedNone
+	reasoningFmt := color.New().Add(color.Italic)
+	return func(delta desktop.ChatDelta) {
+		if delta.Reasoning != "" {
+			if state == printedContent {
+				printContent("\n\n")
+			}
+			if state != printedReasoning {
+				reasoningFmt.Print("Thinking:\n")
+			}
+			state = printedReasoning
+			reasoningFmt.Print(delta.Reasoning)
+		}
+		if delta.Content != "" {
+			if state == printedReasoning {
+				printContent("\n\n--\n\n")
+			}
+			state = printedContent
+			printContent(delta.Content)
+		}
+		if delta.Usage != nil {
+			usageFmt := color.New(color.FgHiBlack)
+			if !colorUsage {
+				usageFmt.DisableColor()
+			}
+			printContent(usageFmt.Sprintf("\n\nToken usage: %d prompt + %d completion = %d total",
+				delta.Usage.PromptTokens,
+				delta.Usage.CompletionTokens,
+				delta.Usage.TotalTokens))
+		}
+	}
+}
+
 // chatWithMarkdown performs chat and streams the response with selective markdown rendering.
-func chatWithMarkdown(cmd *cobra.Command, client *desktop.Client, backend, model, prompt, apiKey string) error {
+// It returns the full response content.
+func chatWithMarkdown(cmd *cobra.Command, client *desktop.Client, backend, model, prompt, apiKey string, opts desktop.ChatOptions) (string, error) {
 	colorMode, _ := cmd.Flags().GetString("color")
 	useMarkdown := shouldUseMarkdown(colorMode)
 	debug, _ := cmd.Flags().GetBool("debug")
 
 	if !useMarkdown {
 		// Simple case: just stream as plain text
-		return client.Chat(backend, model, prompt, apiKey, func(content string) {
+		return client.Chat(cmd.Context(), backend, model, prompt, apiKey, opts, chatPrinter(func(content string) {
 			cmd.Print(content)
-		}, false)
+		}, false))
 	}
 
 	// For markdown: use streaming buffer to render code blocks as they complete
 	markdownBuffer := NewStreamingMarkdownBuffer()
 
-	err := client.Chat(backend, model, prompt, apiKey, func(content string) {
+	response, err := client.Chat(cmd.Context(), backend, model, prompt, apiKey, opts, chatPrinter(func(content string) {
 		// Use the streaming markdown buffer to intelligently render content
 		rendered, err := markdownBuffer.AddContent(content, true)
 		if err != nil {
@@ -289,9 +360,9 @@ func chatWithMarkdown(cmd *cobra.Command, client *desktop.Client, backend, model
 		} else if rendered != "" {
 			cmd.Print(rendered)
 		}
-	}, true)
-	if err != nil {
-		return err
+	}, true))
+	if err != nil && !errors.Is(err, desktop.ErrTruncated) {
+		return "", err
 	}
 
 	// Flush any remaining content from the markdown buffer
@@ -299,20 +370,389 @@ func chatWithMarkdown(cmd *cobra.Command, client *desktop.Client, backend, model
 		cmd.Print(remaining)
 	}
 
+	return response, err
+}
+
+// noteTruncation reports a response stopped by --stop-after-tokens, which
+// isn't an error, and returns any other error as is.
+func noteTruncation(cmd *cobra.Command, err error) error {
+	if !errors.Is(err, desktop.ErrTruncated) {
+		return err
+	}
+	n, _ := cmd.Flags().GetInt("stop-after-tokens")
+	cmd.PrintErrf("\nNote: the response was truncated after %d tokens (--stop-after-tokens)\n", n)
+	return nil
+}
+
+// printCachedResponse prints a cached response the way generateResponse would
+// have printed it while streaming.
+func printCachedResponse(cmd *cobra.Command, response string, raw bool) {
+	colorMode, _ := cmd.Flags().GetString("color")
+	if raw || !shouldUseMarkdown(colorMode) {
+		cmd.Print(response)
+		return
+	}
+	markdownBuffer := NewStreamingMarkdownBuffer()
+	rendered, err := markdownBuffer.AddContent(response, true)
+	if err != nil {
+		cmd.Print(response)
+		return
+	}
+	cmd.Print(rendered)
+	if remaining, err := markdownBuffer.Flush(true); err == nil {
+		cmd.Print(remaining)
+	}
+}
+
+// generateResponse produces a response for a single prompt, either through the
+// chat endpoint or, in raw mode, through the completions endpoint without any
+// chat templating.
+func generateResponse(cmd *cobra.Command, client *desktop.Client, backend, model, prompt, apiKey string, opts desktop.ChatOptions, raw bool) (string, error) {
+	var response string
+	var err error
+	if raw {
+		response, err = client.Complete(cmd.Context(), backend, model, prompt, apiKey, opts, func(content string) {
+			cmd.Print(content)
+		})
+	} else {
+		response, err = chatWithMarkdown(cmd, client, backend, model, prompt, apiKey, opts)
+	}
+	return response, noteTruncation(cmd, err)
+}
+
+// collectResponse produces a response like generateResponse, but returns it
+// without printing it.
+func collectResponse(cmd *cobra.Command, client *desktop.Client, backend, model, prompt, apiKey string, opts desktop.ChatOptions, raw bool) (string, error) {
+	discard := func(string) {}
+	var response string
+	var err error
+	if raw {
+		response, err = client.Complete(cmd.Context(), backend, model, prompt, apiKey, opts, discard)
+	} else {
+		response, err = client.Chat(cmd.Context(), backend, model, prompt, apiKey, opts, func(desktop.ChatDelta) {})
+	}
+	return response, noteTruncation(cmd, err)
+}
+
+// Output formats accepted by run --output-format.
+const (
+	outputFormatText     = "text"
+	outputFormatMarkdown = "markdown"
+)
+
+// markdownTranscript formats a prompt and its response as a self-documenting
+// markdown transcript, with the model and request parameters as a header.
+func markdownTranscript(model, modelID, prompt, response string, params map[string]any, now time.Time) string {
+	var buf strings.Builder
+	buf.WriteString("# Transcript\n\n")
+	if modelID != "" && modelID != model {
+		fmt.Fprintf(&buf, "- Model: %s (%s)\n", model, modelID)
+	} else {
+		fmt.Fprintf(&buf, "- Model: %s\n", model)
+	}
+	fmt.Fprintf(&buf, "- Date: %s\n", now.UTC().Format(time.RFC3339))
+	if len(params) > 0 {
+		keys := slices.Sorted(maps.Keys(params))
+		formatted := make([]string, 0, len(keys))
+		for _, key := range keys {
+			value, _ := json.Marshal(params[key])
+			formatted = append(formatted, fmt.Sprintf("`%s=%s`", key, value))
+		}
+		fmt.Fprintf(&buf, "- Parameters: %s\n", strings.Join(formatted, ", "))
+	}
+	buf.WriteString("\n## Prompt\n\n")
+	buf.WriteString(quotePrompt(prompt))
+	buf.WriteString("\n\n## Response\n\n")
+	buf.WriteString(strings.TrimSpace(response))
+	return buf.String()
+}
+
+// maxTopLogprobs is the largest number of alternatives to each token whose
+// log probabilities can be requested, as defined by the OpenAI API.
+const maxTopLogprobs = 20
+
+// runStreamEvent is a single line of the JSON stream emitted by run when the
+// global --json flag is set. A "delta" event is emitted for each chunk of the
+// response as it arrives, followed by a single "response" event holding the
+// complete response.
+type runStreamEvent struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	// Logprobs holds the log probabilities of the tokens of the preceding
+	// delta in "logprobs" events, which are only emitted with --logprobs.
+	Logprobs []desktop.TokenLogprob `json:"logprobs,omitempty"`
+	// Choices holds the responses in the single "choices" event emitted
+	// instead of the others with --n.
+	Choices []string `json:"choices,omitempty"`
+	// Truncated is set on the "response" event if the response was stopped
+	// by --stop-after-tokens.
+	Truncated bool `json:"truncated,omitempty"`
+	// Stats holds the timings of the response in the "stats" event emitted
+	// after it with --stats.
+	Stats *runStats `json:"stats,omitempty"`
+}
+
+// runStats is the JSON representation of the timings of a response.
+type runStats struct {
+	TimeToFirstTokenMS      float64 `json:"time_to_first_token_ms"`
+	MeanInterTokenLatencyMS float64 `json:"mean_inter_token_latency_ms"`
+	MaxInterTokenLatencyMS  float64 `json:"max_inter_token_latency_ms"`
+	TotalMS                 float64 `json:"total_ms"`
+	Tokens                  int     `json:"tokens"`
+}
+
+func newRunStats(timing desktop.ResponseTiming) *runStats {
+	ms := func(d time.Duration) float64 {
+		return float64(d.Microseconds()) / 1000
+	}
+	return &runStats{
+		TimeToFirstTokenMS:      ms(timing.TimeToFirstToken),
+		MeanInterTokenLatencyMS: ms(timing.MeanInterTokenLatency),
+		MaxInterTokenLatencyMS:  ms(timing.MaxInterTokenLatency),
+		TotalMS:                 ms(timing.Total),
+		Tokens:                  timing.Tokens,
+	}
+}
+
+// formatResponseStats describes the timings of a response for --stats.
+func formatResponseStats(timing desktop.ResponseTiming) string {
+	round := func(d time.Duration) time.Duration {
+		return d.Round(time.Millisecond)
+	}
+	if timing.Tokens == 0 {
+		return fmt.Sprintf("No tokens received in %s\n", round(timing.Total))
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Time to first token:  %s\n", round(timing.TimeToFirstToken))
+	if timing.Tokens > 1 {
+		fmt.Fprintf(&buf, "Inter-token latency:  %s mean, %s max\n",
+			round(timing.MeanInterTokenLatency), round(timing.MaxInterTokenLatency))
+	}
+	fmt.Fprintf(&buf, "Total:                %s for %d tokens\n", round(timing.Total), timing.Tokens)
+	return buf.String()
+}
+
+// printResponseStats prints the timings of the last response, if they were
+// requested with --stats.
+func printResponseStats(cmd *cobra.Command, opts desktop.ChatOptions) {
+	if opts.Timing != nil {
+		cmd.PrintErr(formatResponseStats(*opts.Timing))
+	}
+}
+
+// formatChoices separates several responses to the same prompt with headers
+// numbering them.
+func formatChoices(choices []string) string {
+	var buf strings.Builder
+	for i, choice := range choices {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "--- Choice %d of %d ---\n%s\n", i+1, len(choices), strings.TrimSpace(choice))
+	}
+	return buf.String()
+}
+
+// streamJSONResponse produces a response for a single prompt like
+// generateResponse, but writes it to out as a stream of runStreamEvent lines.
+func streamJSONResponse(ctx context.Context, out io.Writer, client *desktop.Client, backend, model, prompt, apiKey string, opts desktop.ChatOptions, raw bool) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	emit := func(content string) {
+		_ = encoder.Encode(runStreamEvent{Type: "delta", Content: content})
+	}
+	if opts.Logprobs != nil {
+		opts.OnLogprobs = func(logprobs []desktop.TokenLogprob) {
+			_ = encoder.Encode(runStreamEvent{Type: "logprobs", Logprobs: logprobs})
+		}
+	}
+	var response string
+	var err error
+	if raw {
+		response, err = client.Complete(ctx, backend, model, prompt, apiKey, opts, emit)
+	} else {
+		response, err = client.Chat(ctx, backend, model, prompt, apiKey, opts, func(delta desktop.ChatDelta) {
+			if delta.Content != "" {
+				emit(delta.Content)
+			}
+		})
+	}
+	truncated := errors.Is(err, desktop.ErrTruncated)
+	if err != nil && !truncated {
+		return err
+	}
+	if err := encoder.Encode(runStreamEvent{Type: "response", Content: response, Truncated: truncated}); err != nil {
+		return err
+	}
+	if opts.Timing != nil {
+		return encoder.Encode(runStreamEvent{Type: "stats", Stats: newRunStats(*opts.Timing)})
+	}
+	return nil
+}
+
+// readPromptFiles returns the contents of the given files, in order and
+// separated by blank lines.
+func readPromptFiles(paths []string) (string, error) {
+	contents := make([]string, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("--file %s does not exist", path)
+		}
+		if err != nil {
+			return "", fmt.Errorf("unable to read --file %s: %w", path, err)
+		}
+		contents = append(contents, strings.TrimRight(string(content), "\n"))
+	}
+	return strings.Join(contents, "\n\n"), nil
+}
+
+// quotePrompt prefixes each line of a prompt with "> " so that it stands out
+// from the response in transcripts.
+func quotePrompt(prompt string) string {
+	lines := strings.Split(strings.TrimRight(prompt, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// replayChatSession re-runs the prompts of a recorded session. If assertMatch
+// is set, it returns an error if any response differs from the recorded one.
+func replayChatSession(cmd *cobra.Command, client *desktop.Client, session *chatSession, apiKey string, opts desktop.ChatOptions, assertMatch bool) error {
+	var mismatches []int
+	for i, turn := range session.Turns {
+		cmd.Println(quotePrompt(turn.Prompt))
+		response, err := chatWithMarkdown(cmd, client, session.Backend, session.Model, turn.Prompt, apiKey, opts)
+		if err = noteTruncation(cmd, err); err != nil {
+			return handleClientError(err, "Failed to generate a response")
+		}
+		cmd.Println()
+		printResponseStats(cmd, opts)
+		if assertMatch && strings.TrimSpace(response) != strings.TrimSpace(turn.Response) {
+			cmd.PrintErrf("Turn %d: response does not match the recorded response\n", i+1)
+			mismatches = append(mismatches, i+1)
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("replay mismatch on %d of %d turn(s): %v", len(mismatches), len(session.Turns), mismatches)
+	}
 	return nil
 }
 
+// waitForModelLoaded polls the running models until the specified model is
+// loaded or the timeout expires.
+func waitForModelLoaded(ctx context.Context, client *desktop.Client, model, modelID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		ps, err := client.PS()
+		if err != nil {
+			return handleNotRunningError(handleClientError(err, "Failed to list running models"))
+		}
+		if modelRunning(ps, model, modelID) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s waiting for model %s to be loaded", timeout, model)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// modelRunning reports whether model, whose ID is modelID if known, is among
+// the models loaded by the runner.
+func modelRunning(ps []desktop.BackendStatus, model, modelID string) bool {
+	for _, status := range ps {
+		if status.ModelName == model || status.ModelName == model+":latest" ||
+			(modelID != "" && status.ModelName == modelID) {
+			return true
+		}
+	}
+	return false
+}
+
 func newRunCmd() *cobra.Command {
 	var debug bool
 	var backend string
 	var ignoreRuntimeMemoryCheck bool
 	var colorMode string
-
-	const cmdArgs = "MODEL [PROMPT]"
+	var replayPath string
+	var replayAssert bool
+	var raw bool
+	var paramArgs []string
+	var noPull bool
+	var waitForModel time.Duration
+	var continueSession bool
+	var sessionName string
+	var maxTurns int
+	var echoPrompt bool
+	var trim bool
+	var skipArchitectureCheck bool
+	var noValidate bool
+	var useCache bool
+	var noCache bool
+	var cacheTTL time.Duration
+	var outputFormat string
+	var think bool
+	var noThink bool
+	var grammarPath string
+	var system string
+	var systemFile string
+	var promptFiles []string
+	var turnLogPath string
+	var logprobs int
+	var numChoices int
+	var stopAfterTokens int
+	var stats bool
+	var temperature float64
+	var topP float64
+	var maxTokens int
+	var yes bool
+	var saveKey bool
+
+	const cmdArgs = "MODEL [PROMPT | -]"
 	c := &cobra.Command{
 		Use:   "run " + cmdArgs,
 		Short: "Run a model and interact with it using a submitted prompt or chat mode",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := expandPaths(&systemFile, &grammarPath, &turnLogPath, &replayPath); err != nil {
+				return err
+			}
+			if err := expandPathList(promptFiles); err != nil {
+				return err
+			}
+			if replayAssert && replayPath == "" {
+				return fmt.Errorf("--replay-assert can only be used with --replay")
+			}
+			if raw && replayPath != "" {
+				return fmt.Errorf("--raw cannot be used with --replay")
+			}
+			if len(promptFiles) > 0 && replayPath != "" {
+				return fmt.Errorf("--file cannot be used with --replay")
+			}
+			if numChoices < 1 {
+				return fmt.Errorf("--n must be at least 1 (got %d)", numChoices)
+			}
+			if maxTurns < 0 {
+				return fmt.Errorf("--max-turns must not be negative (got %d)", maxTurns)
+			}
+			if raw && (think || noThink) {
+				return fmt.Errorf("--think and --no-think cannot be used with --raw")
+			}
+			if raw && (system != "" || systemFile != "") {
+				return fmt.Errorf("--system and --system-file cannot be used with --raw")
+			}
+			if continueSession && (raw || replayPath != "") {
+				return fmt.Errorf("--continue cannot be used with --raw or --replay")
+			}
+			if sessionName != "" && (continueSession || raw || replayPath != "") {
+				return fmt.Errorf("--session cannot be used with --continue, --raw, or --replay")
+			}
+			if outputFormat != outputFormatText && outputFormat != outputFormatMarkdown {
+				return fmt.Errorf("--output-format must be one of: %s, %s (got %q)", outputFormatText, outputFormatMarkdown, outputFormat)
+			}
 			switch colorMode {
 			case "auto", "yes", "no":
 				return nil
@@ -321,6 +761,36 @@ func newRunCmd() *cobra.Command {
 			}
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// In JSON mode, only the JSON stream is written to the standard
+			// output, and any other output is diverted to the standard error.
+			out := cmd.OutOrStdout()
+			if jsonOutput {
+				cmd.SetOut(cmd.ErrOrStderr())
+			}
+
+			var session *chatSession
+			var manifest *runManifest
+			if replayPath != "" {
+				var err error
+				if session, err = loadChatSession(replayPath); err != nil {
+					return err
+				}
+				if !cmd.Flags().Changed("backend") {
+					backend = session.Backend
+				}
+				session.Backend = backend
+			} else {
+				var err error
+				if manifest, err = loadRunManifest(); err != nil {
+					return err
+				}
+				if manifest != nil && manifest.Backend != "" && !cmd.Flags().Changed("backend") {
+					backend = manifest.Backend
+				} else if backend, err = resolveBackend(cmd, backend); err != nil {
+					return err
+				}
+			}
+
 			// Validate backend if specified
 			if backend != "" {
 				if err := validateBackend(backend); err != nil {
@@ -333,16 +803,159 @@ func newRunCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if saveKey {
+				if backend != "openai" {
+					return fmt.Errorf("--save-key is only available with the openai backend")
+				}
+				if err := saveAPIKey(backend, apiKey); err != nil {
+					return err
+				}
+			}
+
+			params, err := parseParams(paramArgs)
+			if err != nil {
+				return err
+			}
+			if grammarPath != "" {
+				if backend == "openai" {
+					return fmt.Errorf("--grammar is not supported with the openai backend")
+				}
+				if params, err = addGrammarParam(params, grammarPath); err != nil {
+					return err
+				}
+			}
+			var sampling desktop.SamplingOptions
+			if cmd.Flags().Changed("temperature") {
+				if err := checkTemperature("--temperature", temperature); err != nil {
+					return err
+				}
+				sampling.Temperature = &temperature
+			}
+			if cmd.Flags().Changed("top-p") {
+				if err := checkTopP("--top-p", topP); err != nil {
+					return err
+				}
+				sampling.TopP = &topP
+			}
+			if cmd.Flags().Changed("max-tokens") {
+				if err := checkMaxTokens("--max-tokens", maxTokens); err != nil {
+					return err
+				}
+				sampling.MaxTokens = &maxTokens
+			}
+			// requestParams describes the request as a whole, for the
+			// response cache and markdown transcripts.
+			requestParams, err := withSampling(params, sampling)
+			if err != nil {
+				return err
+			}
+			opts := desktop.ChatOptions{Params: params, Sampling: sampling, System: system}
+			if systemFile != "" {
+				content, err := os.ReadFile(systemFile)
+				if err != nil {
+					return fmt.Errorf("unable to read --system-file: %w", err)
+				}
+				opts.System = string(content)
+			}
+			if think || noThink {
+				if backend == "openai" {
+					return fmt.Errorf("--think and --no-think are not supported with the openai backend")
+				}
+				opts.Think = &think
+			}
+			if cmd.Flags().Changed("logprobs") {
+				if !jsonOutput {
+					return fmt.Errorf("--logprobs is only available with --json")
+				}
+				if logprobs < 0 || logprobs > maxTopLogprobs {
+					return fmt.Errorf("--logprobs must be between 0 and %d (got %d)", maxTopLogprobs, logprobs)
+				}
+				opts.Logprobs = &logprobs
+			}
+			if stopAfterTokens < 0 {
+				return fmt.Errorf("--stop-after-tokens must not be negative (got %d)", stopAfterTokens)
+			}
+			opts.StopAfterTokens = stopAfterTokens
+			if stats {
+				opts.Timing = &desktop.ResponseTiming{}
+			}
+
+			var model string
+			promptArgs := args
+			if session != nil {
+				model = session.Model
+			} else if len(args) > 0 && args[0] != "-" {
+				model = args[0]
+				promptArgs = args[1:]
+			} else if manifest != nil && manifest.Model != "" {
+				model = manifest.Model
+			} else {
+				cfg, err := config.Load()
+				if err != nil {
+					return err
+				}
+				if cfg.DefaultModel == "" {
+					return fmt.Errorf(
+						"'docker model run' requires at least 1 argument unless a default model is configured or set in " + manifestFileName + ".\n\n" +
+							"Usage:  docker model run " + cmdArgs + "\n\n" +
+							"See 'docker model run --help' for more information",
+					)
+				}
+				model = cfg.DefaultModel
+			}
+			if slices.Contains(promptArgs, "-") && len(promptArgs) > 1 {
+				return fmt.Errorf("'-' reads the prompt from stdin and cannot be combined with a PROMPT")
+			}
 
-			model := args[0]
 			prompt := ""
-			argsLen := len(args)
-			if argsLen > 1 {
-				prompt = strings.Join(args[1:], " ")
+			if len(promptArgs) == 1 && promptArgs[0] == "-" {
+				// An explicit "-" reads the entire prompt from stdin.
+				input, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("error reading prompt from stdin: %w", err)
+				}
+				if strings.TrimSpace(string(input)) == "" {
+					return fmt.Errorf("no prompt was read from stdin")
+				}
+				prompt = string(input)
+			} else if len(promptArgs) > 0 {
+				prompt = strings.Join(promptArgs, " ")
+			}
+			if len(promptFiles) > 0 {
+				content, err := readPromptFiles(promptFiles)
+				if err != nil {
+					return err
+				}
+				if prompt != "" {
+					prompt += "\n\n"
+				}
+				prompt += content
+			}
+
+			if continueSession && prompt != "" {
+				return fmt.Errorf("--continue resumes an interactive conversation and cannot be used with a PROMPT")
+			}
+
+			var namedSession *chatSession
+			if sessionName != "" {
+				if jsonOutput || numChoices > 1 {
+					return fmt.Errorf("--session cannot be used with --json or --n")
+				}
+				if namedSession, err = loadNamedChatSession(sessionName); err != nil {
+					return err
+				}
+				if namedSession == nil {
+					namedSession = &chatSession{Model: model, Backend: backend}
+				} else if namedSession.Model != model {
+					cmd.PrintErrf("Warning: session %s was started with model %s, continuing it with %s\n",
+						sessionName, namedSession.Model, model)
+					namedSession.Model, namedSession.ModelID = model, ""
+				}
+				opts.History = namedSession.history()
 			}
 
 			fi, err := os.Stdin.Stat()
-			if err == nil && (fi.Mode()&os.ModeCharDevice) == 0 {
+			if session == nil && !slices.Contains(promptArgs, "-") && err == nil && (fi.Mode()&os.ModeCharDevice) == 0 {
 				// Read all from stdin
 				reader := bufio.NewReader(os.Stdin)
 				input, err := io.ReadAll(reader)
@@ -355,6 +968,13 @@ func newRunCmd() *cobra.Command {
 				}
 			}
 
+			if trim && (session != nil || prompt == "" || jsonOutput) {
+				return fmt.Errorf("--trim requires a PROMPT and cannot be used with --json or --replay")
+			}
+			if outputFormat == outputFormatMarkdown && (session != nil || prompt == "" || jsonOutput) {
+				return fmt.Errorf("--output-format markdown requires a PROMPT and cannot be used with --json or --replay")
+			}
+
 			if debug {
 				if prompt == "" {
 					cmd.Printf("Running model %s\n", model)
@@ -367,34 +987,252 @@ func newRunCmd() *cobra.Command {
 				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
 			}
 
-			// Do not validate the model in case of using OpenAI's backend, let OpenAI handle it
-			if backend != "openai" {
-				_, err := desktopClient.Inspect(model, false)
+			// Do not validate the model in case of using OpenAI's backend, let OpenAI handle it.
+			// With --no-validate, errors surface from the chat request instead.
+			var modelID string
+			if backend != "openai" && !noValidate {
+				inspected, err := desktopClient.Inspect(model, false)
 				if err != nil {
 					if !errors.Is(err, desktop.ErrNotFound) {
 						return handleNotRunningError(handleClientError(err, "Failed to inspect model"))
 					}
+					if noPull {
+						return fmt.Errorf("model %s not found locally and --no-pull is set", model)
+					}
+					// Check the model against the backend before downloading
+					// it, if the registry can be reached.
+					var remote *desktop.Model
+					if !isOffline() {
+						if m, err := desktopClient.Inspect(model, true); err == nil {
+							remote = &m
+							if !skipArchitectureCheck {
+								if err := checkModelSupported(backend, m); err != nil {
+									return err
+								}
+							}
+						}
+					}
+					if !yes && !confirmPull(cmd, model, remote) {
+						return fmt.Errorf("model %s not found locally and pulling it was declined", model)
+					}
 					cmd.Println("Unable to find model '" + model + "' locally. Pulling from the server.")
-					if err := pullModel(cmd, desktopClient, model, ignoreRuntimeMemoryCheck); err != nil {
+					if err := pullModel(cmd, desktopClient, model, defaultPullOptions(ignoreRuntimeMemoryCheck)); err != nil {
+						return err
+					}
+					if inspected, err = desktopClient.Inspect(model, false); err != nil {
+						return handleNotRunningError(handleClientError(err, "Failed to inspect model"))
+					}
+				}
+				modelID = inspected.ID
+				if !skipArchitectureCheck {
+					if err := checkModelSupported(backend, inspected); err != nil {
 						return err
 					}
 				}
 			}
 
-			if prompt != "" {
-				if err := chatWithMarkdown(cmd, desktopClient, backend, model, prompt, apiKey); err != nil {
+			if manifest != nil && manifest.Model == model && manifest.configures() && backend != "openai" {
+				// The runner refuses to reconfigure a model that is already
+				// loaded, so the options only apply when it's next loaded.
+				if ps, err := desktopClient.PS(); err == nil && modelRunning(ps, model, modelID) {
+					if debug {
+						cmd.Printf("Model %s is already loaded, not applying the runtime options from %s\n", model, manifestFileName)
+					}
+				} else if err := desktopClient.ConfigureBackend(scheduling.ConfigureRequest{
+					Model:        model,
+					ContextSize:  manifest.ContextSize,
+					RuntimeFlags: manifest.RuntimeFlags,
+				}); err != nil {
+					cmd.PrintErrf("Warning: unable to apply the runtime options from %s: %v\n", manifestFileName, err)
+				}
+			}
+
+			if waitForModel > 0 && backend != "openai" {
+				start := time.Now()
+				if err := waitForModelLoaded(cmd.Context(), desktopClient, model, modelID, waitForModel); err != nil {
+					return err
+				}
+				if debug {
+					cmd.Printf("Model %s loaded after %s\n", model, time.Since(start).Round(time.Millisecond))
+				}
+			}
+
+			if useCache || cmd.Flags().Changed("cache-ttl") {
+				if noCache {
+					return fmt.Errorf("--cache and --cache-ttl cannot be used with --no-cache")
+				}
+				if jsonOutput || session != nil || prompt == "" {
+					return fmt.Errorf("--cache is only available in single prompt mode")
+				}
+				if stopAfterTokens > 0 {
+					return fmt.Errorf("--cache cannot be used with --stop-after-tokens")
+				}
+			}
+
+			if numChoices > 1 {
+				if session != nil || prompt == "" {
+					return fmt.Errorf("--n requires a PROMPT; interactive mode and --replay are not supported")
+				}
+				if trim || outputFormat == outputFormatMarkdown || useCache || cmd.Flags().Changed("cache-ttl") || opts.Logprobs != nil || stopAfterTokens > 0 || stats {
+					return fmt.Errorf("--n cannot be used with --trim, --output-format markdown, --cache, --logprobs, --stop-after-tokens, or --stats")
+				}
+			}
+
+			if turnLogPath != "" && (jsonOutput || session != nil || prompt != "") {
+				return fmt.Errorf("--turn-log is only available in interactive mode")
+			}
+
+			if jsonOutput {
+				if session != nil || prompt == "" {
+					return fmt.Errorf("--json requires a PROMPT; interactive mode and --replay are not supported")
+				}
+				if echoPrompt {
+					return fmt.Errorf("--echo-prompt cannot be used with --json")
+				}
+				if numChoices > 1 {
+					choices, err := desktopClient.Sample(cmd.Context(), backend, model, prompt, apiKey, numChoices, opts, raw)
+					if err != nil {
+						return handleClientError(err, "Failed to generate responses")
+					}
+					encoder := json.NewEncoder(out)
+					encoder.SetEscapeHTML(false)
+					return encoder.Encode(runStreamEvent{Type: "choices", Choices: choices})
+				}
+				if err := streamJSONResponse(cmd.Context(), out, desktopClient, backend, model, prompt, apiKey, opts, raw); err != nil {
 					return handleClientError(err, "Failed to generate a response")
 				}
+				return nil
+			}
+
+			if session != nil {
+				if session.ModelID != "" && modelID != "" && session.ModelID != modelID {
+					cmd.PrintErrf("Warning: model %s resolves to %s, but the session was recorded with %s\n",
+						model, modelID, session.ModelID)
+				}
+				return replayChatSession(cmd, desktopClient, session, apiKey, opts, replayAssert)
+			}
+
+			if prompt != "" {
+				markdown := outputFormat == outputFormatMarkdown
+				if echoPrompt && !markdown {
+					cmd.Println(quotePrompt(prompt))
+				}
+				if numChoices > 1 {
+					choices, err := desktopClient.Sample(cmd.Context(), backend, model, prompt, apiKey, numChoices, opts, raw)
+					if err != nil {
+						return handleClientError(err, "Failed to generate responses")
+					}
+					cmd.Print(formatChoices(choices))
+					return nil
+				}
+				cache, err := resolveResponseCache(useCache, noCache, cacheTTL, cmd.Flags().Changed("cache-ttl"))
+				if err != nil {
+					return err
+				}
+				if stopAfterTokens > 0 || namedSession != nil {
+					// Truncated responses mustn't be reused for requests
+					// without the cap, and responses in a session depend on
+					// its earlier turns.
+					cache = nil
+				}
+				var cacheKey string
+				if cache != nil {
+					cacheModel := model
+					if modelID != "" {
+						cacheModel = modelID
+					}
+					if cacheKey, err = responseCacheKey(cacheModel, backend, opts.System, prompt, raw, requestParams, opts.Think); err != nil {
+						return err
+					}
+				}
+				// Trimmed and markdown output can only be printed once the
+				// response is complete.
+				generate := generateResponse
+				if trim || markdown {
+					generate = collectResponse
+				}
+				response, cached := "", false
+				if cache != nil {
+					response, cached = cache.get(cacheKey, time.Now())
+				}
+				if cached {
+					if !trim && !markdown {
+						printCachedResponse(cmd, response, raw)
+					}
+				} else {
+					response, err = generate(cmd, desktopClient, backend, model, prompt, apiKey, opts, raw)
+					if err != nil {
+						if backend == "openai" || errors.Is(err, context.Canceled) || !offerRepair(cmd, desktopClient, model, err) {
+							return handleClientError(err, "Failed to generate a response")
+						}
+						if response, err = generate(cmd, desktopClient, backend, model, prompt, apiKey, opts, raw); err != nil {
+							return handleClientError(err, "Failed to generate a response")
+						}
+					}
+					if cache != nil {
+						if err := cache.put(cacheKey, response, time.Now()); err != nil {
+							cmd.PrintErrf("Warning: %v\n", err)
+						}
+					}
+				}
+				if namedSession != nil {
+					namedSession.ModelID = modelID
+					namedSession.Turns = append(namedSession.Turns, chatTurn{Prompt: prompt, Response: response})
+					if err := namedSession.saveNamed(sessionName); err != nil {
+						cmd.PrintErrf("Warning: %v\n", err)
+					}
+				}
+				switch {
+				case markdown:
+					cmd.Print(markdownTranscript(model, modelID, prompt, response, requestParams, time.Now()))
+				case trim:
+					cmd.Print(strings.TrimSpace(response))
+				}
 				cmd.Println()
+				if !cached {
+					printResponseStats(cmd, opts)
+				}
 				return nil
 			}
 
+			var turnLogger *turnLog
+			if turnLogPath != "" {
+				if turnLogger, err = openTurnLog(turnLogPath); err != nil {
+					return err
+				}
+				defer turnLogger.Close()
+			}
+
+			session = &chatSession{Model: model, ModelID: modelID, Backend: backend}
+			if namedSession != nil {
+				session = namedSession
+				session.ModelID = modelID
+				if len(session.Turns) > 0 {
+					cmd.Printf("Continuing session %s (%d turns).\n", sessionName, len(session.Turns))
+				}
+			} else if continueSession {
+				last, err := loadLastChatSession(model)
+				if err != nil {
+					return err
+				}
+				if last == nil {
+					cmd.Printf("No previous conversation with %s found, starting a new one.\n", model)
+				} else {
+					session.Turns = last.Turns
+					cmd.Printf("Continuing the previous conversation with %s (%d turns).\n", model, len(last.Turns))
+				}
+			}
 			scanner := bufio.NewScanner(os.Stdin)
-			cmd.Println("Interactive chat mode started. Type '/bye' to exit.")
+			cmd.Println("Interactive chat mode started. Type '/bye' to exit, '/save' to keep the conversation for --continue, '/save FILE' to export it, or '/set KEY VALUE' and '/show' to adjust the sampling parameters.")
 
+			turns := 0
 			for {
-				userInput, err := readMultilineInput(cmd, scanner)
+				userInput, err := readInteractiveInput(cmd.Context(), cmd, scanner)
 				if err != nil {
+					if errors.Is(err, context.Canceled) {
+						cmd.Println()
+						return err
+					}
 					if err.Error() == "EOF" {
 						cmd.Println("\nChat session ended.")
 						break
@@ -411,34 +1249,165 @@ func newRunCmd() *cobra.Command {
 					continue
 				}
 
-				if err := chatWithMarkdown(cmd, desktopClient, backend, model, userInput, apiKey); err != nil {
+				if fields := strings.Fields(userInput); fields[0] == "/save" {
+					if len(fields) > 2 {
+						cmd.PrintErrln("Usage: /save [FILE]")
+						continue
+					}
+					if len(fields) == 1 {
+						// Without a file, keep the conversation so that it
+						// can be resumed with --continue.
+						if err := session.saveLast(); err != nil {
+							cmd.PrintErrln(err)
+							continue
+						}
+						cmd.Printf("Conversation saved, resume it with 'docker model run %s --continue'\n", model)
+						continue
+					}
+					if err := session.save(fields[1]); err != nil {
+						cmd.PrintErrln(err)
+						continue
+					}
+					cmd.Printf("Session saved to %s\n", fields[1])
+					continue
+				}
+				if fields := strings.Fields(userInput); fields[0] == "/set" {
+					if len(fields) != 3 {
+						cmd.PrintErrln("Usage: /set temperature|top_p|max_tokens VALUE|default")
+						continue
+					}
+					if err := setSampling(&opts.Sampling, fields[1], fields[2]); err != nil {
+						cmd.PrintErrln(err)
+						continue
+					}
+					cmd.Printf("Set %s to %s\n", fields[1], fields[2])
+					continue
+				}
+				if strings.TrimSpace(userInput) == "/show" {
+					cmd.Print(formatSampling(opts.Sampling))
+					continue
+				}
+
+				if continueSession || namedSession != nil {
+					opts.History = session.history()
+				}
+				response, err := generateResponse(cmd, desktopClient, backend, model, userInput, apiKey, opts, raw)
+				if errors.Is(err, context.Canceled) {
+					cmd.Println()
+					return err
+				}
+				if err != nil {
 					cmd.PrintErr(handleClientError(err, "Failed to generate a response"))
+					if backend != "openai" && offerRepair(cmd, desktopClient, model, err) {
+						cmd.Println("Model repaired, please resubmit your prompt.")
+					}
 					continue
 				}
+				session.Turns = append(session.Turns, chatTurn{Prompt: userInput, Response: response})
+				turns++
+				// A conversation resumed with --continue is persisted after
+				// every turn, so that it can be resumed again even if the chat
+				// is interrupted.
+				if continueSession {
+					if err := session.saveLast(); err != nil {
+						cmd.PrintErrf("Warning: %v\n", err)
+					}
+				}
+				if namedSession != nil {
+					if err := session.saveNamed(sessionName); err != nil {
+						cmd.PrintErrf("Warning: %v\n", err)
+					}
+				}
+				if turnLogger != nil {
+					// The sampling parameters may have changed with /set.
+					turnParams, err := withSampling(params, opts.Sampling)
+					if err != nil {
+						turnParams = params
+					}
+					if err := turnLogger.append(turnRecord{
+						Time:     time.Now(),
+						Model:    model,
+						ModelID:  modelID,
+						Backend:  backend,
+						System:   opts.System,
+						Params:   turnParams,
+						Think:    opts.Think,
+						Turn:     len(session.Turns),
+						Prompt:   userInput,
+						Response: response,
+					}); err != nil {
+						cmd.PrintErrf("Warning: %v\n", err)
+					}
+				}
 
 				cmd.Println()
+				printResponseStats(cmd, opts)
+				if maxTurns > 0 && turns == maxTurns {
+					cmd.Printf("Reached the limit of %d turn(s). Chat session ended.\n", maxTurns)
+					break
+				}
 			}
 			return nil
 		},
 		ValidArgsFunction: completion.ModelNames(getDesktopClient, 1),
 	}
 	c.Args = func(cmd *cobra.Command, args []string) error {
-		if len(args) < 1 {
-			return fmt.Errorf(
-				"'docker model run' requires at least 1 argument.\n\n" +
-					"Usage:  docker model run " + cmdArgs + "\n\n" +
-					"See 'docker model run --help' for more information",
-			)
+		if replayPath != "" {
+			if len(args) > 0 {
+				return fmt.Errorf(
+					"'docker model run' does not take MODEL or PROMPT when --replay is specified.\n\n" +
+						"See 'docker model run --help' for more information",
+				)
+			}
+			return nil
 		}
-
+		// If no MODEL is specified, the configured default model is used. That
+		// check is deferred to RunE because the configuration directory isn't
+		// known until the root command's PersistentPreRunE has run.
 		return nil
 	}
 
 	c.Flags().BoolVar(&debug, "debug", false, "Enable debug logging")
 	c.Flags().StringVar(&backend, "backend", "", fmt.Sprintf("Specify the backend to use (%s)", ValidBackendsKeys()))
 	c.Flags().MarkHidden("backend")
+	c.Flags().BoolVar(&saveKey, "save-key", false, "Save the OPENAI_API_KEY for later runs with the openai backend, which use it when the variable isn't set")
+	c.Flags().DurationVar(&waitForModel, "wait-for-model", 0, "Wait up to the specified duration for the model to be loaded before sending prompts")
+	c.Flags().Lookup("wait-for-model").NoOptDefVal = "1m"
+	c.Flags().BoolVar(&noPull, "no-pull", false, "Fail instead of pulling the model if it is not available locally")
+	c.Flags().BoolVarP(&yes, "yes", "y", false, "Pull the model without asking for confirmation if it is not available locally")
+	c.Flags().BoolVar(&noValidate, "no-validate", false, "Send requests without checking that the model is available locally or pulling it")
+	c.Flags().BoolVar(&skipArchitectureCheck, "skip-architecture-check", false, "Run the model even if the backend isn't known to support its format or architecture")
 	c.Flags().BoolVar(&ignoreRuntimeMemoryCheck, "ignore-runtime-memory-check", false, "Do not block pull if estimated runtime memory for model exceeds system resources.")
 	c.Flags().StringVar(&colorMode, "color", "auto", "Use colored output (auto|yes|no)")
+	c.Flags().BoolVar(&raw, "raw", false, "Send prompts to the completions endpoint without chat templating (no system prompt or roles are applied)")
+	c.Flags().BoolVar(&think, "think", false, "Ask models that support a thinking mode to reason before responding")
+	c.Flags().BoolVar(&noThink, "no-think", false, "Ask models that support a thinking mode not to generate reasoning at all")
+	c.MarkFlagsMutuallyExclusive("think", "no-think")
+	c.Flags().IntVar(&numChoices, "n", 1, "Generate the given number of responses to the prompt (single prompt mode only)")
+	c.Flags().IntVar(&stopAfterTokens, "stop-after-tokens", 0, "Stop printing the response after the given number of tokens, on top of any limit set with --max-tokens (0 for no limit)")
+	c.Flags().Float64Var(&temperature, "temperature", 0, "Sampling temperature; lower values make responses more deterministic (default: the backend's)")
+	c.Flags().Float64Var(&topP, "top-p", 0, "Only sample from the most likely tokens whose probabilities add up to the given value (default: the backend's)")
+	c.Flags().IntVar(&maxTokens, "max-tokens", 0, "Maximum number of tokens to generate per response (default: the backend's)")
+	c.Flags().BoolVar(&stats, "stats", false, "Print the time to first token and the latency between tokens after each response")
+	c.Flags().IntVar(&logprobs, "logprobs", 0, "Include the log probabilities of the generated tokens, and of the given number of most likely alternatives to each, in the JSON output")
+	c.Flags().StringArrayVar(&promptFiles, "file", nil, "Add the contents of the given file to the prompt (repeatable); files are separated by blank lines")
+	c.Flags().StringVar(&system, "system", "", "Send a system prompt ahead of every prompt to steer the model's behavior")
+	c.Flags().StringVar(&systemFile, "system-file", "", "Read the system prompt from the given file")
+	c.MarkFlagsMutuallyExclusive("system", "system-file")
+	c.Flags().StringVar(&grammarPath, "grammar", "", "Constrain the response with the GBNF grammar in the given file (llama.cpp only)")
+	c.Flags().StringArrayVar(&paramArgs, "param", nil, "Set a request parameter as key=value (repeatable); unknown parameters are forwarded verbatim to the backend")
+	c.Flags().StringVar(&outputFormat, "output-format", outputFormatText, "Format of the response in single prompt mode (text|markdown); markdown adds the prompt, model, date, and parameters")
+	c.Flags().BoolVar(&trim, "trim", false, "Print the response only once it is complete, without leading or trailing whitespace (single prompt mode only)")
+	c.Flags().BoolVar(&useCache, "cache", false, "Reuse the response to an identical earlier prompt, and cache new responses (single prompt mode only)")
+	c.Flags().BoolVar(&noCache, "no-cache", false, "Neither read nor write the response cache, even if the response-cache-ttl setting is set")
+	c.Flags().DurationVar(&cacheTTL, "cache-ttl", defaultResponseCacheTTL, "How long cached responses are reused (implies --cache)")
+	c.Flags().BoolVar(&echoPrompt, "echo-prompt", false, "Print the prompt, prefixed with '> ', before the response (single prompt mode only)")
+	c.Flags().IntVar(&maxTurns, "max-turns", 0, "End interactive chat after the specified number of turns (0 for unlimited)")
+	c.Flags().BoolVar(&continueSession, "continue", false, "Resume the last interactive conversation with the model, and keep it for the next --continue")
+	c.Flags().StringVar(&turnLogPath, "turn-log", "", "Append each completed turn of the interactive chat to the given file as a JSON line, with the model and request parameters")
+	c.Flags().StringVar(&sessionName, "session", "", "Keep the conversation in the named session, and continue it if it exists, in both single prompt and interactive mode")
+	c.Flags().StringVar(&replayPath, "replay", "", "Re-run the prompts of a session exported with /save")
+	c.Flags().BoolVar(&replayAssert, "replay-assert", false, "Fail if replayed responses differ from the recorded ones (only available with --replay)")
 
 	return c
 }
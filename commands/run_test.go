@@ -2,9 +2,13 @@ package commands
 
 import (
 	"bufio"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/docker/model-cli/desktop"
 	"github.com/spf13/cobra"
 )
 
@@ -113,3 +117,57 @@ func TestReadMultilineInputUnclosed(t *testing.T) {
 		t.Errorf("readMultilineInput() error should mention unclosed multiline input, got: %v", err)
 	}
 }
+
+func TestMarkdownTranscript(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	params := map[string]any{"temperature": 0.2, "stop": []any{"\n"}}
+	got := markdownTranscript("ai/smollm2", "sha256:1234", "line 1\nline 2", "\nHello!\n", params, now)
+	expected := "# Transcript\n\n" +
+		"- Model: ai/smollm2 (sha256:1234)\n" +
+		"- Date: 2024-06-01T12:00:00Z\n" +
+		"- Parameters: `stop=[\"\\n\"]`, `temperature=0.2`\n\n" +
+		"## Prompt\n\n" +
+		"> line 1\n> line 2\n\n" +
+		"## Response\n\n" +
+		"Hello!"
+	if got != expected {
+		t.Errorf("markdownTranscript() = %q, want %q", got, expected)
+	}
+}
+
+func TestChatPrinter(t *testing.T) {
+	var output strings.Builder
+	printDelta := chatPrinter(func(s string) { output.WriteString(s) }, false)
+	printDelta(desktop.ChatDelta{Content: "Hello"})
+	printDelta(desktop.ChatDelta{Content: "!"})
+	printDelta(desktop.ChatDelta{Usage: &desktop.ChatUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}})
+	expected := "Hello!\n\nToken usage: 3 prompt + 2 completion = 5 total"
+	if got := output.String(); got != expected {
+		t.Errorf("chatPrinter() printed %q, want %q", got, expected)
+	}
+}
+
+func TestReadPromptFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	if err := os.WriteFile(first, []byte("Summarize:\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("line 1\nline 2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readPromptFiles([]string{first, second})
+	if err != nil {
+		t.Fatalf("readPromptFiles() error = %v", err)
+	}
+	if expected := "Summarize:\n\nline 1\nline 2"; got != expected {
+		t.Errorf("readPromptFiles() = %q, want %q", got, expected)
+	}
+
+	missing := filepath.Join(dir, "missing.txt")
+	if _, err := readPromptFiles([]string{first, missing}); err == nil || !strings.Contains(err.Error(), missing+" does not exist") {
+		t.Errorf("readPromptFiles() error = %v, want it to name the missing file", err)
+	}
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/standalone"
+	"github.com/docker/model-cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// redactedValue replaces secrets in the output of runner-config.
+const redactedValue = "<redacted>"
+
+// secretConfigKeys are the Docker config file fields that hold secrets.
+var secretConfigKeys = map[string]bool{
+	"auth":          true,
+	"password":      true,
+	"identitytoken": true,
+	"registrytoken": true,
+}
+
+func newRunnerConfigCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "runner-config",
+		Short: "Show the Docker config file used by the standalone Docker Model Runner",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			engineKind := modelRunner.EngineKind()
+			if engineKind != types.ModelRunnerEngineKindMoby && engineKind != types.ModelRunnerEngineKindCloud {
+				return errors.New("runner-config is only available with a standalone model runner")
+			}
+			dockerClient, err := desktop.DockerClientForContext(dockerCLI, dockerCLI.CurrentContext())
+			if err != nil {
+				return fmt.Errorf("failed to create Docker client: %w", err)
+			}
+			ctrID, ctrName, _, err := standalone.FindControllerContainer(cmd.Context(), dockerClient, runnerName)
+			if err != nil {
+				return fmt.Errorf("unable to identify Model Runner container: %w", err)
+			} else if ctrID == "" {
+				return errors.New("unable to identify Model Runner container")
+			}
+			data, err := standalone.ReadControllerDockerConfig(cmd.Context(), dockerClient, ctrID)
+			if err != nil {
+				return err
+			}
+			if data == nil {
+				cmd.Printf("No Docker config file was copied into Model Runner container %s\n", ctrName)
+				return nil
+			}
+			redacted, err := redactDockerConfig(data)
+			if err != nil {
+				return err
+			}
+			cmd.Print(redacted)
+			return nil
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	return c
+}
+
+// redactDockerConfig returns an indented copy of a Docker config file with
+// its secrets redacted. The user names encoded in auth fields are kept, so
+// that it's still possible to tell which credentials are configured.
+func redactDockerConfig(data []byte) (string, error) {
+	var cfg any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("unable to parse Docker config file: %w", err)
+	}
+	redactSecrets(cfg)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cfg); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func redactSecrets(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			secret, ok := field.(string)
+			if !secretConfigKeys[strings.ToLower(key)] || !ok {
+				redactSecrets(field)
+				continue
+			}
+			if secret == "" {
+				continue
+			}
+			v[key] = redactedValue
+			if strings.EqualFold(key, "auth") {
+				if decoded, err := base64.StdEncoding.DecodeString(secret); err == nil {
+					if username, _, ok := strings.Cut(string(decoded), ":"); ok {
+						v[key] = username + ":" + redactedValue
+					}
+				}
+			}
+		}
+	case []any:
+		for _, item := range v {
+			redactSecrets(item)
+		}
+	}
+}
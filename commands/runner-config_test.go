@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactDockerConfig(t *testing.T) {
+	// dXNlcjpzM2NyZXQ= is "user:s3cret".
+	config := `{
+		"auths": {
+			"https://index.docker.io/v1/": {"auth": "dXNlcjpzM2NyZXQ="},
+			"registry.example.com": {"identitytoken": "token-value", "auth": ""}
+		},
+		"credsStore": "desktop"
+	}`
+	redacted, err := redactDockerConfig([]byte(config))
+	if err != nil {
+		t.Fatalf("redactDockerConfig() error = %v", err)
+	}
+	for _, secret := range []string{"s3cret", "dXNlcjpzM2NyZXQ=", "token-value"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("redacted config contains %q:\n%s", secret, redacted)
+		}
+	}
+	for _, kept := range []string{`"user:<redacted>"`, `"identitytoken": "<redacted>"`, `"credsStore": "desktop"`} {
+		if !strings.Contains(redacted, kept) {
+			t.Errorf("redacted config doesn't contain %s:\n%s", kept, redacted)
+		}
+	}
+
+	if _, err := redactDockerConfig([]byte("not json")); err == nil {
+		t.Error("redactDockerConfig() should fail on invalid JSON")
+	}
+}
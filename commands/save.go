@@ -0,0 +1,336 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/standalone"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/spf13/cobra"
+)
+
+func newSaveCmd() *cobra.Command {
+	var output string
+	var remote bool
+	var compress string
+	c := &cobra.Command{
+		Use:   "save MODEL [MODEL...] -o FILE",
+		Short: "Save models to a single bundle file",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf(
+					"'docker model save' requires at least 1 argument.\n\n" +
+						"Usage:  docker model save MODEL [MODEL...] -o FILE\n\n" +
+						"See 'docker model save --help' for more information",
+				)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" {
+				return fmt.Errorf("--output is required")
+			}
+			if err := expandPaths(&output); err != nil {
+				return err
+			}
+			if compress != "" && compress != compressionGzip && compress != compressionZstd {
+				return fmt.Errorf("--compress must be %s or %s (got %q)", compressionGzip, compressionZstd, compress)
+			}
+			if remote {
+				if err := ensureOnline("save models"); err != nil {
+					return err
+				}
+				return saveModels(cmd, args, output, compress, registrySource(cmd))
+			}
+			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
+				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
+			}
+			return saveModels(cmd, args, output, compress, runnerSource(cmd, desktopClient))
+		},
+		ValidArgsFunction: completion.ModelNames(getDesktopClient, -1),
+	}
+	c.Flags().StringVarP(&output, "output", "o", "", "Write the bundle to the given file")
+	c.Flags().BoolVarP(&remote, "remote", "r", false, "Save the models from their registry instead of the model runner")
+	c.Flags().StringVar(&compress, "compress", "", "Compress the bundle (gzip|zstd)")
+	return c
+}
+
+// modelSource writes models to a bundle. prepare is called first with all
+// models and returns the digests of their manifests and the total size to
+// save, or 0 if it's unknown. save then writes each model and returns the
+// digest of its manifest.
+type modelSource struct {
+	prepare func(models []string) ([]v1.Hash, uint64, error)
+	save    func(bundle *bundleWriter, model string, report func(int)) (v1.Hash, error)
+}
+
+// runnerSource saves models stored in the model runner.
+func runnerSource(cmd *cobra.Command, desktopClient *desktop.Client) modelSource {
+	return modelSource{
+		prepare: func(models []string) ([]v1.Hash, uint64, error) {
+			// A model's ID is the digest of its manifest.
+			digests := make([]v1.Hash, len(models))
+			for i, model := range models {
+				inspected, err := desktopClient.Inspect(model, false)
+				if err != nil {
+					return nil, 0, handleNotRunningError(handleClientError(err, "Failed to get model "+model))
+				}
+				if digests[i], err = v1.NewHash(inspected.ID); err != nil {
+					return nil, 0, fmt.Errorf("invalid ID of %s: %w", model, err)
+				}
+			}
+			// The manifests, which give the total, can only be read from
+			// the model storage of standalone model runners. Without them,
+			// the progress doesn't show the total.
+			dockerClient, containerID, err := standaloneController(cmd.Context(), "the size can only be determined")
+			if err != nil {
+				return digests, 0, nil
+			}
+			counted := make(map[v1.Hash]bool)
+			var total uint64
+			for i, model := range models {
+				raw, err := standalone.StoredManifest(cmd.Context(), dockerClient, containerID, digests[i].String())
+				if err != nil {
+					return nil, 0, fmt.Errorf("failed to get manifest of %s: %w", model, err)
+				}
+				manifest, err := v1.ParseManifest(bytes.NewReader(raw))
+				if err != nil {
+					return nil, 0, fmt.Errorf("invalid manifest of %s: %w", model, err)
+				}
+				for _, desc := range manifestBlobs(manifest) {
+					if !counted[desc.Digest] {
+						counted[desc.Digest] = true
+						total += uint64(desc.Size)
+					}
+				}
+			}
+			return digests, total, nil
+		},
+		save: func(bundle *bundleWriter, model string, report func(int)) (v1.Hash, error) {
+			pr, pw := io.Pipe()
+			errCh := make(chan error, 1)
+			go func() {
+				err := desktopClient.SaveModel(cmd.Context(), model, pw)
+				pw.CloseWithError(err)
+				errCh <- err
+			}()
+			digest, addErr := bundle.addModelArchive(pr, report)
+			pr.Close()
+			if err := <-errCh; err != nil {
+				if errors.Is(err, desktop.ErrSaveUnsupported) {
+					return v1.Hash{}, fmt.Errorf("%w; update Docker Model Runner, or use --remote to save the model from its registry", err)
+				}
+				return v1.Hash{}, handleNotRunningError(handleClientError(err, "Failed to save model"))
+			}
+			return digest, addErr
+		},
+	}
+}
+
+// registrySource saves models fetched from their registry.
+func registrySource(cmd *cobra.Command) modelSource {
+	var images []v1.Image
+	return modelSource{
+		prepare: func(models []string) ([]v1.Hash, uint64, error) {
+			keychain, err := registryKeychain()
+			if err != nil {
+				return nil, 0, err
+			}
+			counted := make(map[v1.Hash]bool)
+			var digests []v1.Hash
+			var total uint64
+			for _, model := range models {
+				ref, err := name.ParseReference(model)
+				if err != nil {
+					return nil, 0, fmt.Errorf("invalid model reference %q: %w", model, err)
+				}
+				image, err := remote.Image(ref, remote.WithContext(cmd.Context()), remote.WithAuthFromKeychain(keychain))
+				if err != nil {
+					return nil, 0, fmt.Errorf("failed to get %s from the registry: %w", model, err)
+				}
+				digest, err := image.Digest()
+				if err != nil {
+					return nil, 0, err
+				}
+				manifest, err := image.Manifest()
+				if err != nil {
+					return nil, 0, err
+				}
+				for _, layer := range manifest.Layers {
+					if !counted[layer.Digest] {
+						counted[layer.Digest] = true
+						total += uint64(layer.Size)
+					}
+				}
+				images = append(images, image)
+				digests = append(digests, digest)
+			}
+			return digests, total, nil
+		},
+		save: func(bundle *bundleWriter, _ string, report func(int)) (v1.Hash, error) {
+			image := images[0]
+			images = images[1:]
+			if err := saveImage(bundle, image, report); err != nil {
+				return v1.Hash{}, err
+			}
+			return image.Digest()
+		},
+	}
+}
+
+// saveModels writes a bundle of models to output. Blobs shared by several
+// models are only stored once. The bundle is written to a temporary file that
+// is renamed to output once it is complete, so that an interrupted save
+// doesn't leave a partial bundle behind. The bundle is compressed in the given
+// format, if any, as it is written.
+func saveModels(cmd *cobra.Command, models []string, output, compression string, source modelSource) error {
+	digests, total, err := source.prepare(models)
+	if err != nil {
+		return err
+	}
+	// The bundle is only written to output once it is complete, so one that
+	// holds the same models doesn't need to be saved again.
+	if bundleHolds(output, compression, models, digests) {
+		cmd.Printf("%s already holds %d model(s), skipping\n", output, len(models))
+		return nil
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+"-*")
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", output, err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	printProgress, inPlace, err := progressPrinter()
+	if err != nil {
+		return err
+	}
+	step := 1
+	if !inPlace {
+		step = pullProgressStep
+	}
+	progress, flush := steppedProgress(step, printProgress)
+	var saved uint64
+	report := func(n int) {
+		saved += uint64(n)
+		message := "Saving: " + formatSize(int64(saved))
+		if total > 0 {
+			message += " of " + formatSize(int64(total))
+		}
+		progress(desktop.PullProgress{Message: message, Current: saved, Total: total})
+	}
+	if total == 0 && inPlace {
+		// Without a total, every update is shown.
+		report = func(n int) {
+			saved += uint64(n)
+			printProgress("Saving: " + formatSize(int64(saved)))
+		}
+	}
+
+	written := &countingWriter{w: f}
+	cw, err := compressWriter(written, compression)
+	if err != nil {
+		return err
+	}
+	uncompressed := &countingWriter{w: cw}
+	bundle := newBundleWriter(uncompressed)
+	var index bundleIndex
+	for _, model := range models {
+		digest, err := source.save(bundle, model, report)
+		if err != nil {
+			if inPlace && saved > 0 {
+				cmd.Println()
+			}
+			return fmt.Errorf("failed to save %s: %w", model, err)
+		}
+		index.Models = append(index.Models, bundleModel{Reference: model, Manifest: digest.String()})
+	}
+	flush()
+	if inPlace && saved > 0 {
+		cmd.Println()
+	}
+	if err := bundle.writeIndex(index); err != nil {
+		return fmt.Errorf("unable to write %s: %w", output, err)
+	}
+	if err := bundle.Close(); err != nil {
+		return fmt.Errorf("unable to write %s: %w", output, err)
+	}
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("unable to write %s: %w", output, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write %s: %w", output, err)
+	}
+	if err := os.Rename(f.Name(), output); err != nil {
+		return fmt.Errorf("unable to write %s: %w", output, err)
+	}
+	for _, model := range index.Models {
+		cmd.Printf("Saved %s (%s)\n", model.Reference, model.Manifest)
+	}
+	if compression != "" {
+		cmd.Printf("Saved %d model(s) to %s (%s, %s compressed with %s)\n", len(index.Models), output,
+			formatSize(int64(uncompressed.n)), formatSize(int64(written.n)), compression)
+		return nil
+	}
+	cmd.Printf("Saved %d model(s) to %s (%s)\n", len(index.Models), output, formatSize(int64(saved)))
+	return nil
+}
+
+// saveImage writes the layers, config, and manifest of a model to a bundle.
+func saveImage(bundle *bundleWriter, image v1.Image, report func(int)) error {
+	layers, err := image.Layers()
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return err
+		}
+		if bundle.has(digest) {
+			continue
+		}
+		size, err := layer.Size()
+		if err != nil {
+			return err
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			return err
+		}
+		err = bundle.writeBlob(digest, size, &countingReader{r: rc, count: report})
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	configName, err := image.ConfigName()
+	if err != nil {
+		return err
+	}
+	config, err := image.RawConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := bundle.writeBlob(configName, int64(len(config)), bytes.NewReader(config)); err != nil {
+		return err
+	}
+	digest, err := image.Digest()
+	if err != nil {
+		return err
+	}
+	manifest, err := image.RawManifest()
+	if err != nil {
+		return err
+	}
+	return bundle.writeBlob(digest, int64(len(manifest)), bytes.NewReader(manifest))
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newScanCmd() *cobra.Command {
+	var remote bool
+	c := &cobra.Command{
+		Use:   "scan MODEL",
+		Short: "Check a model against the configured scan policy",
+		Long: "Check a model against the configured scan policy.\n\n" +
+			"The policy is read from the " + config.KeyScanAllowedArchitectures + " and " + config.KeyScanMaxSize +
+			" settings (see 'docker model config'). Models that aren't available locally are checked using the " +
+			"information from the registry, so they can be scanned before they are pulled.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf(
+					"'docker model scan' requires 1 argument.\n\n" +
+						"Usage:  docker model scan MODEL\n\n" +
+						"See 'docker model scan --help' for more information",
+				)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			policy, err := newScanPolicy(cfg)
+			if err != nil {
+				return err
+			}
+			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
+				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
+			}
+			model, err := inspectForScan(cmd, desktopClient, args[0], remote)
+			if err != nil {
+				return err
+			}
+			failed := 0
+			for _, result := range policy.check(model) {
+				status := "PASS"
+				if !result.passed {
+					status = "FAIL"
+					failed++
+				}
+				cmd.Printf("%s  %s\n", status, result.reason)
+			}
+			if failed > 0 {
+				return fmt.Errorf("model %s failed %d scan check(s)", args[0], failed)
+			}
+			return nil
+		},
+		ValidArgsFunction: completion.ModelNames(getDesktopClient, 1),
+	}
+	c.Flags().BoolVarP(&remote, "remote", "r", false, "Check the model in the registry, even if it is available locally")
+	return c
+}
+
+// inspectForScan inspects the model to scan, falling back to the registry if
+// it isn't available locally.
+func inspectForScan(cmd *cobra.Command, desktopClient *desktop.Client, model string, remote bool) (desktop.Model, error) {
+	inspect := func(remote bool) (desktop.Model, error) {
+		inspected, err := desktopClient.Inspect(model, remote)
+		if err != nil {
+			return desktop.Model{}, handleNotRunningError(handleClientError(err, "Failed to get model "+model))
+		}
+		return inspected, nil
+	}
+	if remote {
+		if err := ensureOnline("scan remote models"); err != nil {
+			return desktop.Model{}, err
+		}
+		return inspect(true)
+	}
+	return withRemoteFallback(cmd, model, "scan remote models", "checking the registry", inspect)
+}
+
+// scanPolicy holds the conditions a model must satisfy to pass a scan.
+type scanPolicy struct {
+	// architectures are the allowed model architectures. Any architecture is
+	// allowed if it's empty.
+	architectures []string
+	// maxSize is the largest allowed model size in bytes, or 0 for no limit.
+	// Like the model sizes reported by the runner, it uses binary units.
+	maxSize int64
+}
+
+// newScanPolicy builds the scan policy from the configuration.
+func newScanPolicy(cfg *config.Config) (scanPolicy, error) {
+	var policy scanPolicy
+	for _, arch := range strings.Split(cfg.ScanAllowedArchitectures, ",") {
+		if arch = strings.TrimSpace(arch); arch != "" {
+			policy.architectures = append(policy.architectures, strings.ToLower(arch))
+		}
+	}
+	if cfg.ScanMaxSize != "" {
+		var err error
+		if policy.maxSize, err = units.RAMInBytes(cfg.ScanMaxSize); err != nil {
+			return scanPolicy{}, fmt.Errorf("invalid %s setting: %w", config.KeyScanMaxSize, err)
+		}
+	}
+	if len(policy.architectures) == 0 && policy.maxSize == 0 {
+		return scanPolicy{}, fmt.Errorf("no scan policy is configured; use 'docker model config set' to set %s or %s",
+			config.KeyScanAllowedArchitectures, config.KeyScanMaxSize)
+	}
+	return policy, nil
+}
+
+// scanResult is the outcome of a single policy check.
+type scanResult struct {
+	passed bool
+	reason string
+}
+
+// check evaluates the policy against a model.
+func (p scanPolicy) check(m desktop.Model) []scanResult {
+	var results []scanResult
+	if len(p.architectures) > 0 {
+		arch := m.Config.Architecture
+		switch {
+		case arch == "":
+			results = append(results, scanResult{reason: "architecture is unknown"})
+		case slices.Contains(p.architectures, strings.ToLower(arch)):
+			results = append(results, scanResult{passed: true, reason: fmt.Sprintf("architecture %s is allowed", arch)})
+		default:
+			results = append(results, scanResult{reason: fmt.Sprintf("architecture %s is not allowed (allowed: %s)",
+				arch, strings.Join(p.architectures, ", "))})
+		}
+	}
+	if p.maxSize > 0 {
+		size, err := units.RAMInBytes(m.Config.Size)
+		switch {
+		case m.Config.Size == "" || err != nil:
+			results = append(results, scanResult{reason: "size is unknown"})
+		case size > p.maxSize:
+			results = append(results, scanResult{reason: fmt.Sprintf("size %s exceeds the maximum of %s",
+				m.Config.Size, units.BytesSize(float64(p.maxSize)))})
+		default:
+			results = append(results, scanResult{passed: true, reason: fmt.Sprintf("size %s is within the maximum of %s",
+				m.Config.Size, units.BytesSize(float64(p.maxSize)))})
+		}
+	}
+	return results
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/model-cli/desktop"
+	"github.com/docker/model-cli/pkg/config"
+	"github.com/docker/model-distribution/types"
+	dmrm "github.com/docker/model-runner/pkg/inference/models"
+)
+
+func TestScanPolicy(t *testing.T) {
+	policy, err := newScanPolicy(&config.Config{ScanAllowedArchitectures: "llama, Qwen2", ScanMaxSize: "1GB"})
+	if err != nil {
+		t.Fatalf("newScanPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		config types.Config
+		passed []bool
+	}{
+		{name: "allowed", config: types.Config{Architecture: "qwen2", Size: "256.35 MiB"}, passed: []bool{true, true}},
+		{name: "disallowed architecture", config: types.Config{Architecture: "gemma3", Size: "256.35 MiB"}, passed: []bool{false, true}},
+		{name: "too large", config: types.Config{Architecture: "llama", Size: "4.58 GiB"}, passed: []bool{true, false}},
+		{name: "at the maximum", config: types.Config{Architecture: "llama", Size: "1 GiB"}, passed: []bool{true, true}},
+		{name: "unknown", passed: []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := policy.check(desktop.Model{Model: dmrm.Model{Config: tt.config}})
+			if len(results) != len(tt.passed) {
+				t.Fatalf("check() returned %d results, want %d", len(results), len(tt.passed))
+			}
+			for i, result := range results {
+				if result.passed != tt.passed[i] {
+					t.Errorf("check()[%d] = %+v, want passed = %v", i, result, tt.passed[i])
+				}
+			}
+		})
+	}
+
+	if _, err := newScanPolicy(&config.Config{}); err == nil {
+		t.Errorf("newScanPolicy() should return an error without a policy")
+	}
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func newSessionCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "session",
+		Short: "Manage the conversations kept with run --session",
+	}
+	c.AddCommand(
+		newSessionListCmd(),
+		newSessionRemoveCmd(),
+	)
+	return c
+}
+
+func newSessionListCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List sessions",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessions, err := listNamedChatSessions()
+			if err != nil {
+				return err
+			}
+			cmd.Print(sessionsTable(sessions, time.Now()))
+			return nil
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	return c
+}
+
+func newSessionRemoveCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "rm NAME [NAME...]",
+		Short: "Remove sessions",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf(
+					"'docker model session rm' requires at least 1 argument.\n\n" +
+						"Usage:  docker model session rm NAME [NAME...]\n\n" +
+						"See 'docker model session rm --help' for more information",
+				)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var errs []error
+			for _, name := range args {
+				if err := removeNamedChatSession(name); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				cmd.Println(name)
+			}
+			return errors.Join(errs...)
+		},
+		ValidArgsFunction: sessionNames,
+	}
+	return c
+}
+
+// sessionNames completes arguments with the names of the sessions.
+func sessionNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	sessions, err := listNamedChatSessions()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		names = append(names, session.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func sessionsTable(sessions []namedSessionInfo, now time.Time) string {
+	var buf bytes.Buffer
+	table := tablewriter.NewWriter(&buf)
+
+	table.SetHeader([]string{"NAME", "MODEL", "TURNS", "UPDATED"})
+
+	table.SetBorder(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderLine(false)
+	table.SetTablePadding("  ")
+	table.SetNoWhiteSpace(true)
+
+	table.SetColumnAlignment([]int{
+		tablewriter.ALIGN_LEFT, // NAME
+		tablewriter.ALIGN_LEFT, // MODEL
+		tablewriter.ALIGN_LEFT, // TURNS
+		tablewriter.ALIGN_LEFT, // UPDATED
+	})
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, session := range sessions {
+		table.Append([]string{
+			session.Name,
+			session.Model,
+			strconv.Itoa(session.Turns),
+			units.HumanDuration(now.Sub(session.Updated)) + " ago",
+		})
+	}
+
+	table.Render()
+	return buf.String()
+}
@@ -13,7 +13,6 @@ import (
 )
 
 func newStatusCmd() *cobra.Command {
-	var formatJson bool
 	c := &cobra.Command{
 		Use:   "status",
 		Short: "Check if the Docker Model Runner is running",
@@ -36,7 +35,7 @@ func newStatusCmd() *cobra.Command {
 				cmd.PrintErrln(fmt.Errorf("failed to parse status response: %w", err))
 			}
 
-			if formatJson {
+			if jsonOutput {
 				return jsonStatus(standalone, status, backendStatus)
 			} else {
 				textStatus(cmd, status, backendStatus)
@@ -46,7 +45,6 @@ func newStatusCmd() *cobra.Command {
 		},
 		ValidArgsFunction: completion.NoComplete,
 	}
-	c.Flags().BoolVar(&formatJson, "json", false, "Format output in JSON")
 	return c
 }
 
@@ -12,13 +12,13 @@ import (
 
 func newTagCmd() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "tag SOURCE TARGET",
+		Use:   "tag SOURCE TARGET [TARGET...]",
 		Short: "Tag a model",
 		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 2 {
+			if len(args) < 2 {
 				return fmt.Errorf(
-					"'docker model tag' requires 2 arguments.\n\n" +
-						"Usage:  docker model tag SOURCE TARGET\n\n" +
+					"'docker model tag' requires at least 2 arguments.\n\n" +
+						"Usage:  docker model tag SOURCE TARGET [TARGET...]\n\n" +
 						"See 'docker model tag --help' for more information",
 				)
 			}
@@ -28,21 +28,58 @@ func newTagCmd() *cobra.Command {
 			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
 				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
 			}
-			return tagModel(cmd, desktopClient, args[0], args[1])
+			return tagModels(cmd, desktopClient, args[0], args[1:])
 		},
 		ValidArgsFunction: completion.ModelNames(getDesktopClient, 1),
 	}
 	return c
 }
 
-func tagModel(cmd *cobra.Command, desktopClient *desktop.Client, source, target string) error {
+// tagModels tags source with each of targets, resolving source only once. It
+// tries every target even if some fail, and then reports which did.
+func tagModels(cmd *cobra.Command, desktopClient *desktop.Client, source string, targets []string) error {
+	model, err := desktopClient.Inspect(source, false)
+	if err != nil {
+		err = handleClientError(err, "Failed to get model "+source)
+		return handleNotRunningError(err)
+	}
+	if len(targets) == 1 {
+		return tagModel(cmd, desktopClient, source, model, targets[0])
+	}
+	var tagged, failed []string
+	for _, target := range targets {
+		if err := tagModel(cmd, desktopClient, source, model, target); err != nil {
+			cmd.PrintErrf("Failed to tag model %q with %q: %v\n", source, target, err)
+			failed = append(failed, target)
+			continue
+		}
+		tagged = append(tagged, target)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(tagged) == 0 {
+		return fmt.Errorf("failed to tag model %q with any of the %d tags", source, len(targets))
+	}
+	return fmt.Errorf("model %q was tagged with %s, but not with %s",
+		source, strings.Join(tagged, ", "), strings.Join(failed, ", "))
+}
+
+// tagModel tags source, which resolves to model, with target.
+func tagModel(cmd *cobra.Command, desktopClient *desktop.Client, source string, model desktop.Model, target string) error {
 	// Ensure tag is valid
 	tag, err := name.NewTag(target)
 	if err != nil {
 		return fmt.Errorf("invalid tag: %w", err)
 	}
+	// Tagging is idempotent: if the source model already has the target tag,
+	// there's nothing to do.
+	if hasTag(model, tag) {
+		cmd.Printf("Model %q is already tagged with %q\n", source, target)
+		return nil
+	}
 	// Make tag request with model runner client
-	if err := desktopClient.Tag(source, parseRepo(tag), tag.TagStr()); err != nil {
+	if err := desktopClient.Tag(model.ID, parseRepo(tag), tag.TagStr()); err != nil {
 		return fmt.Errorf("failed to tag model: %w", err)
 	}
 	cmd.Printf("Model %q tagged successfully with %q\n", source, target)
@@ -54,3 +91,14 @@ func tagModel(cmd *cobra.Command, desktopClient *desktop.Client, source, target
 func parseRepo(tag name.Tag) string {
 	return strings.TrimSuffix(tag.String(), ":"+tag.TagStr())
 }
+
+// hasTag reports whether model has tag, however the registry and tag are
+// spelled in its tags.
+func hasTag(model desktop.Model, tag name.Tag) bool {
+	for _, t := range model.Tags {
+		if existing, err := name.NewTag(t); err == nil && existing.Name() == tag.Name() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/model-cli/desktop"
+	dmrm "github.com/docker/model-runner/pkg/inference/models"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func TestHasTag(t *testing.T) {
+	model := desktop.Model{Model: dmrm.Model{ID: "sha256:354bf30d0aa3", Tags: []string{"ai/smollm2:latest", "smollm2:mine"}}}
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"ai/smollm2", true},
+		{"docker.io/ai/smollm2:latest", true},
+		{"smollm2:mine", true},
+		{"index.docker.io/library/smollm2:mine", true},
+		{"smollm2", false},
+		{"ai/smollm2:360M", false},
+	}
+	for _, tt := range tests {
+		tag, err := name.NewTag(tt.target)
+		if err != nil {
+			t.Fatalf("name.NewTag(%q): %v", tt.target, err)
+		}
+		if got := hasTag(model, tag); got != tt.want {
+			t.Errorf("hasTag(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
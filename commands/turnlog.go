@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// turnRecord is a line of a turn log, describing a completed interactive
+// turn along with the request that produced it.
+type turnRecord struct {
+	Time     time.Time      `json:"time"`
+	Model    string         `json:"model"`
+	ModelID  string         `json:"model_id,omitempty"`
+	Backend  string         `json:"backend,omitempty"`
+	System   string         `json:"system,omitempty"`
+	Params   map[string]any `json:"params,omitempty"`
+	Think    *bool          `json:"think,omitempty"`
+	Turn     int            `json:"turn"`
+	Prompt   string         `json:"prompt"`
+	Response string         `json:"response"`
+}
+
+// turnLog appends the turns of interactive chats to a JSON Lines file, such
+// as for building datasets from real conversations.
+type turnLog struct {
+	f *os.File
+}
+
+// openTurnLog opens the turn log at path, creating it if it doesn't exist.
+func openTurnLog(path string) (*turnLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open turn log: %w", err)
+	}
+	return &turnLog{f: f}, nil
+}
+
+// append writes a record as a single line. The line is written at once, so
+// that concurrent chats logging to the same file don't interleave records.
+func (l *turnLog) append(record turnRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error marshaling turn: %w", err)
+	}
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("unable to write turn log: %w", err)
+	}
+	return nil
+}
+
+func (l *turnLog) Close() error {
+	return l.f.Close()
+}
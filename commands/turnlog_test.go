@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTurnLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "turns.jsonl")
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Every chat appends to the log, which is created by the first one.
+	for i, prompt := range []string{"Hi", "Bye"} {
+		log, err := openTurnLog(path)
+		if err != nil {
+			t.Fatalf("openTurnLog() error = %v", err)
+		}
+		if err := log.append(turnRecord{Time: now, Model: "ai/smollm2", Turn: i + 1, Prompt: prompt, Response: prompt + "!"}); err != nil {
+			t.Fatalf("append() error = %v", err)
+		}
+		if err := log.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var records []turnRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record turnRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("turn log holds %d records, want 2", len(records))
+	}
+	if records[1].Prompt != "Bye" || records[1].Response != "Bye!" || records[1].Turn != 2 || !records[1].Time.Equal(now) {
+		t.Errorf("second record = %+v", records[1])
+	}
+}
@@ -25,7 +25,7 @@ func newUninstallRunner() *cobra.Command {
 				cmd.Println("Use `docker desktop disable model-runner` instead")
 				return nil
 			} else if kind == types.ModelRunnerEngineKindMobyManual {
-				cmd.Println("Standalone uninstallation not supported with MODEL_RUNNER_HOST set")
+				cmd.Println("Standalone uninstallation not supported with MODEL_RUNNER_HOST or default-host set")
 				return nil
 			}
 
@@ -36,7 +36,7 @@ func newUninstallRunner() *cobra.Command {
 			}
 
 			// Remove any model runner containers.
-			if err := standalone.PruneControllerContainers(cmd.Context(), dockerClient, false, cmd); err != nil {
+			if err := standalone.PruneControllerContainers(cmd.Context(), dockerClient, false, runnerName, cmd); err != nil {
 				return fmt.Errorf("unable to remove model runner container(s): %w", err)
 			}
 
@@ -49,7 +49,7 @@ func newUninstallRunner() *cobra.Command {
 
 			// Remove model storage, if requested.
 			if models {
-				if err := standalone.PruneModelStorageVolumes(cmd.Context(), dockerClient, cmd); err != nil {
+				if err := standalone.PruneModelStorageVolumes(cmd.Context(), dockerClient, runnerName, cmd); err != nil {
 					return fmt.Errorf("unable to remove model storage volume(s): %w", err)
 				}
 			}
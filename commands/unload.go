@@ -34,7 +34,7 @@ func newUnloadCmd() *cobra.Command {
 			}
 			return nil
 		},
-		ValidArgsFunction: completion.NoComplete,
+		ValidArgsFunction: completion.LoadedModelNames(getDesktopClient, -1),
 	}
 	c.Args = func(cmd *cobra.Command, args []string) error {
 		if all {
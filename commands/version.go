@@ -1,20 +1,80 @@
 package commands
 
 import (
+	"runtime"
+
 	"github.com/docker/model-cli/commands/completion"
+	"github.com/docker/model-cli/commands/formatter"
 	"github.com/docker/model-cli/desktop"
 	"github.com/spf13/cobra"
 )
 
+// versionInfo describes the CLI build and the detected engine.
+type versionInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	BuildDate  string `json:"build_date,omitempty"`
+	GoVersion  string `json:"go_version"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	EngineKind string `json:"engine_kind"`
+}
+
 func newVersionCmd() *cobra.Command {
+	var format string
 	c := &cobra.Command{
 		Use:   "version",
 		Short: "Show the Docker Model Runner version",
-		Run: func(cmd *cobra.Command, args []string) {
-			cmd.Printf("Docker Model Runner version %s\n", desktop.Version)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := versionInfo{
+				Version:    desktop.Version,
+				Commit:     desktop.GitCommit,
+				BuildDate:  desktop.BuildDate,
+				GoVersion:  runtime.Version(),
+				OS:         runtime.GOOS,
+				Arch:       runtime.GOARCH,
+				EngineKind: modelRunner.EngineKind().String(),
+			}
+			if jsonOutput || format == "json" {
+				output, err := formatter.ToStandardJSON(info)
+				if err != nil {
+					return err
+				}
+				cmd.Print(output)
+				return nil
+			}
+			if format != "" {
+				tmpl, err := formatter.ParseTemplate(format)
+				if err != nil {
+					return err
+				}
+				output, err := tmpl.Execute(info)
+				if err != nil {
+					return err
+				}
+				cmd.Print(output)
+				return nil
+			}
+			cmd.Printf("Docker Model Runner version %s%s\n", desktop.Version, buildDetails(info))
 			cmd.Printf("Docker Engine Kind: %s\n", modelRunner.EngineKind())
+			return nil
 		},
 		ValidArgsFunction: completion.NoComplete,
 	}
+	c.Flags().StringVar(&format, "format", "", "Format the output as json or using the given Go template (e.g. '{{.Commit}}')")
 	return c
 }
+
+// buildDetails summarizes the build metadata that was injected at build time,
+// if any.
+func buildDetails(info versionInfo) string {
+	switch {
+	case info.Commit != "" && info.BuildDate != "":
+		return " (commit " + info.Commit + ", built " + info.BuildDate + ")"
+	case info.Commit != "":
+		return " (commit " + info.Commit + ")"
+	case info.BuildDate != "":
+		return " (built " + info.BuildDate + ")"
+	}
+	return ""
+}
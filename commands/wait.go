@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/docker/model-cli/commands/completion"
+	"github.com/spf13/cobra"
+)
+
+func newWaitCmd() *cobra.Command {
+	var timeout time.Duration
+	c := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for the Docker Model Runner to be ready",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if timeout <= 0 {
+				return fmt.Errorf("--timeout must be positive (got %s)", timeout)
+			}
+			if _, err := ensureStandaloneRunnerAvailable(cmd.Context(), cmd); err != nil {
+				return fmt.Errorf("unable to initialize standalone model runner: %w", err)
+			}
+			start := time.Now()
+			deadline := time.NewTimer(timeout)
+			defer deadline.Stop()
+			for !runnerReady() {
+				select {
+				case <-time.After(installWaitRetryInterval):
+				case <-deadline.C:
+					return fmt.Errorf("model runner not ready after %s", timeout)
+				case <-cmd.Context().Done():
+					return cmd.Context().Err()
+				}
+			}
+			cmd.Printf("Docker Model Runner is ready (waited %s)\n", time.Since(start).Round(time.Millisecond))
+			return nil
+		},
+		ValidArgsFunction: completion.NoComplete,
+	}
+	c.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "Maximum time to wait for the runner to be ready")
+	return c
+}
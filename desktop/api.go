@@ -1,5 +1,30 @@
 package desktop
 
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	dmrm "github.com/docker/model-runner/pkg/inference/models"
+)
+
+// Model describes a model as reported by the model runner. It extends
+// dmrm.Model with fields that newer runners may report before they're part of
+// the upstream type.
+type Model struct {
+	dmrm.Model
+	// Labels are the labels attached to the model artifact, if the runner
+	// reports any.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Extra holds the fields of the runner's response that Model doesn't
+	// know about, so that they aren't lost when the model is printed.
+	Extra map[string]json.RawMessage `json:"-"`
+	// ConfigExtra is like Extra, for the fields of the model's config.
+	ConfigExtra map[string]json.RawMessage `json:"-"`
+}
+
 // ProgressMessage represents a structured message for progress reporting
 type ProgressMessage struct {
 	Type    string `json:"type"`    // "progress", "success", or "error"
@@ -20,10 +45,121 @@ type OpenAIChatMessage struct {
 	Content string `json:"content"`
 }
 
+// ChatOptions holds optional settings for chat and completion requests.
+type ChatOptions struct {
+	// Params are additional request fields that are forwarded verbatim to the
+	// backend. They never override fields set by the client itself.
+	Params map[string]any
+	// Sampling holds the sampling parameters set by the client.
+	Sampling SamplingOptions
+	// System, if set, is sent as a system message ahead of the history and
+	// the prompt. It is ignored by completion requests.
+	System string
+	// History holds the earlier messages of a conversation, which are sent
+	// ahead of the prompt. It is ignored by completion requests.
+	History []OpenAIChatMessage
+	// Think, if set, asks the model to enable or disable its thinking mode.
+	// It is ignored by completion requests.
+	Think *bool
+	// Logprobs, if set, requests the log probabilities of the generated
+	// tokens, along with the given number of most likely alternatives at each
+	// position. They're passed to OnLogprobs as they arrive.
+	Logprobs   *int
+	OnLogprobs func([]TokenLogprob)
+	// StopAfterTokens, if positive, stops the response once that many tokens
+	// have been streamed, regardless of the backend's limits. Tokens are
+	// counted as stream deltas, each of which usually holds one token.
+	StopAfterTokens int
+	// Timing, if set, is filled in with the timings of the response.
+	Timing *ResponseTiming
+}
+
+// ResponseTiming holds the timings of a streamed response, as measured by the
+// client. Tokens are counted as stream deltas.
+type ResponseTiming struct {
+	// TimeToFirstToken is the time from sending the request to receiving the
+	// first token.
+	TimeToFirstToken time.Duration
+	// MeanInterTokenLatency and MaxInterTokenLatency describe the time
+	// between consecutive tokens.
+	MeanInterTokenLatency time.Duration
+	MaxInterTokenLatency  time.Duration
+	// Total is the time from sending the request to the end of the response.
+	Total  time.Duration
+	Tokens int
+
+	start time.Time
+	last  time.Time
+}
+
+// begin resets the timings when the request is sent. The methods of
+// ResponseTiming do nothing on a nil receiver, so that callers don't have to
+// check whether timings were requested.
+func (t *ResponseTiming) begin(now time.Time) {
+	if t != nil {
+		*t = ResponseTiming{start: now}
+	}
+}
+
+// token records the arrival of a token.
+func (t *ResponseTiming) token(now time.Time) {
+	if t == nil {
+		return
+	}
+	if t.Tokens == 0 {
+		t.TimeToFirstToken = now.Sub(t.start)
+	} else {
+		t.MaxInterTokenLatency = max(t.MaxInterTokenLatency, now.Sub(t.last))
+	}
+	t.Tokens++
+	t.last = now
+}
+
+// end records the end of the response.
+func (t *ResponseTiming) end(now time.Time) {
+	if t == nil {
+		return
+	}
+	t.Total = now.Sub(t.start)
+	if t.Tokens > 1 {
+		t.MeanInterTokenLatency = t.last.Sub(t.start.Add(t.TimeToFirstToken)) / time.Duration(t.Tokens-1)
+	}
+}
+
+// SamplingOptions are the sampling parameters of chat and completion
+// requests. Parameters that aren't set are left to the backend's defaults.
+type SamplingOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// TokenLogprob is the log probability of a generated token.
+type TokenLogprob struct {
+	Token       string       `json:"token"`
+	Logprob     float64      `json:"logprob"`
+	TopLogprobs []TopLogprob `json:"top_logprobs,omitempty"`
+}
+
+// TopLogprob is the log probability of one of the most likely tokens at a
+// position.
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+}
+
 type OpenAIChatRequest struct {
 	Model    string              `json:"model"`
 	Messages []OpenAIChatMessage `json:"messages"`
 	Stream   bool                `json:"stream"`
+	SamplingOptions
+	// ChatTemplateKwargs are passed to the model's chat template by backends
+	// that support it (llama.cpp and vLLM).
+	ChatTemplateKwargs map[string]any `json:"chat_template_kwargs,omitempty"`
+	Logprobs           bool           `json:"logprobs,omitempty"`
+	TopLogprobs        *int           `json:"top_logprobs,omitempty"`
+	// N is the number of responses to generate.
+	N int `json:"n,omitempty"`
 }
 
 type OpenAIChatResponse struct {
@@ -39,10 +175,82 @@ type OpenAIChatResponse struct {
 		} `json:"delta"`
 		Index        int    `json:"index"`
 		FinishReason string `json:"finish_reason"`
+		Logprobs     *struct {
+			Content []TokenLogprob `json:"content"`
+		} `json:"logprobs,omitempty"`
 	} `json:"choices"`
-	Usage *struct {
-		CompletionTokens int `json:"completion_tokens"`
-		PromptTokens     int `json:"prompt_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage,omitempty"`
+	Usage *ChatUsage `json:"usage,omitempty"`
+}
+
+// ChatUsage reports the number of tokens used by a chat request.
+type ChatUsage struct {
+	CompletionTokens int `json:"completion_tokens"`
+	PromptTokens     int `json:"prompt_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatDelta is a part of a streamed chat response. Reasoning content, which
+// thinking models generate before their response, is kept apart from the
+// response content.
+type ChatDelta struct {
+	Content   string
+	Reasoning string
+	// Usage is only set on the last delta, if the backend reports it.
+	Usage *ChatUsage
+}
+
+type OpenAICompletionRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+	SamplingOptions
+	Logprobs *int `json:"logprobs,omitempty"`
+	N        int  `json:"n,omitempty"`
+}
+
+type OpenAICompletionResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Text         string              `json:"text"`
+		Index        int                 `json:"index"`
+		FinishReason string              `json:"finish_reason"`
+		Logprobs     *completionLogprobs `json:"logprobs,omitempty"`
+	} `json:"choices"`
+}
+
+// completionLogprobs holds the log probabilities of a completion, either in
+// the format of chat completions, as reported by llama.cpp, or in the legacy
+// format of the OpenAI completions API.
+type completionLogprobs struct {
+	Content       []TokenLogprob       `json:"content"`
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []float64            `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+}
+
+// tokens returns the log probabilities in the format of chat completions.
+func (l completionLogprobs) tokens() []TokenLogprob {
+	if len(l.Content) > 0 {
+		return l.Content
+	}
+	tokens := make([]TokenLogprob, 0, len(l.Tokens))
+	for i, token := range l.Tokens {
+		t := TokenLogprob{Token: token}
+		if i < len(l.TokenLogprobs) {
+			t.Logprob = l.TokenLogprobs[i]
+		}
+		if i < len(l.TopLogprobs) {
+			for _, top := range slices.Sorted(maps.Keys(l.TopLogprobs[i])) {
+				t.TopLogprobs = append(t.TopLogprobs, TopLogprob{Token: top, Logprob: l.TopLogprobs[i][top]})
+			}
+			slices.SortStableFunc(t.TopLogprobs, func(a, b TopLogprob) int {
+				return cmp.Compare(b.Logprob, a.Logprob)
+			})
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
 }
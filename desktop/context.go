@@ -14,6 +14,7 @@ import (
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/context/docker"
 	clientpkg "github.com/docker/docker/client"
+	"github.com/docker/model-cli/pkg/config"
 	"github.com/docker/model-cli/pkg/standalone"
 	"github.com/docker/model-cli/pkg/types"
 	"github.com/docker/model-runner/pkg/inference"
@@ -105,14 +106,30 @@ func NewContextForMock(client DockerHttpClient) *ModelRunnerContext {
 	}
 }
 
+// DetectOptions adjusts the detection of the Docker Model Runner context.
+type DetectOptions struct {
+	// TreatDesktopAsMoby makes Docker Desktop contexts behave like Docker
+	// Engine ones, with a standalone model runner. This is only for testing
+	// purposes.
+	TreatDesktopAsMoby bool
+	// PreferStandalone makes Docker Desktop contexts use a standalone model
+	// runner instead of the built-in one. Unlike TreatDesktopAsMoby, it
+	// doesn't change how the standalone model runner is set up.
+	PreferStandalone bool
+}
+
 // DetectContext determines the current Docker Model Runner context.
-func DetectContext(ctx context.Context, cli *command.DockerCli) (*ModelRunnerContext, error) {
-	// Check for an explicit endpoint setting.
+func DetectContext(ctx context.Context, cli *command.DockerCli, opts DetectOptions) (*ModelRunnerContext, error) {
+	// Check for an explicit endpoint setting, falling back to the configured
+	// default host.
 	modelRunnerHost := os.Getenv("MODEL_RUNNER_HOST")
-
-	// Check if we're treating Docker Desktop as regular Moby. This is only for
-	// testing purposes.
-	treatDesktopAsMoby := os.Getenv("_MODEL_RUNNER_TREAT_DESKTOP_AS_MOBY") == "1"
+	if modelRunnerHost == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		modelRunnerHost = cfg.DefaultHost
+	}
 
 	// Detect the associated engine type.
 	kind := types.ModelRunnerEngineKindMoby
@@ -120,7 +137,7 @@ func DetectContext(ctx context.Context, cli *command.DockerCli) (*ModelRunnerCon
 		kind = types.ModelRunnerEngineKindMobyManual
 	} else if isDesktopContext(ctx, cli) {
 		kind = types.ModelRunnerEngineKindDesktop
-		if treatDesktopAsMoby {
+		if opts.TreatDesktopAsMoby || opts.PreferStandalone {
 			kind = types.ModelRunnerEngineKindMoby
 		}
 	} else if isCloudContext(cli) {
@@ -183,6 +200,15 @@ func (c *ModelRunnerContext) URL(path string) string {
 	return result
 }
 
+// SetStandalonePort points the context at a standalone model runner listening
+// on the given host port. It has no effect for other engine kinds.
+func (c *ModelRunnerContext) SetStandalonePort(port uint16) {
+	if c.kind != types.ModelRunnerEngineKindMoby && c.kind != types.ModelRunnerEngineKindCloud {
+		return
+	}
+	c.urlPrefix = &url.URL{Scheme: "http", Host: "localhost:" + strconv.Itoa(int(port))}
+}
+
 // Client returns an HTTP client appropriate for accessing the model runner.
 func (c *ModelRunnerContext) Client() DockerHttpClient {
 	return c.client
@@ -5,13 +5,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"html"
 	"io"
+	"maps"
+	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/docker/go-units"
@@ -19,9 +23,9 @@ import (
 	"github.com/docker/model-runner/pkg/inference"
 	dmrm "github.com/docker/model-runner/pkg/inference/models"
 	"github.com/docker/model-runner/pkg/inference/scheduling"
-	"github.com/fatih/color"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
+	"golang.org/x/sync/errgroup"
 )
 
 const DefaultBackend = "llama.cpp"
@@ -29,6 +33,13 @@ const DefaultBackend = "llama.cpp"
 var (
 	ErrNotFound           = errors.New("model not found")
 	ErrServiceUnavailable = errors.New("service unavailable")
+	// ErrSaveUnsupported is returned by SaveModel if the model runner can't
+	// export models.
+	ErrSaveUnsupported = errors.New("this model runner is too old to save models")
+	ErrUnauthorized    = errors.New("registry authentication failed")
+	// ErrTruncated is returned along with the partial response when a
+	// response is stopped after ChatOptions.StopAfterTokens tokens.
+	ErrTruncated = errors.New("response truncated")
 )
 
 type otelErrorSilencer struct{}
@@ -82,6 +93,12 @@ func (c *Client) Status() Status {
 		}
 	}
 	defer resp.Body.Close()
+	if err := checkContentType(resp, nil); err != nil {
+		return Status{
+			Running: false,
+			Error:   err,
+		}
+	}
 	if resp.StatusCode == http.StatusOK {
 		var status []byte
 		statusResp, err := c.doRequest(http.MethodGet, inference.InferencePrefix+"/status", nil)
@@ -107,32 +124,178 @@ func (c *Client) Status() Status {
 	}
 }
 
-func (c *Client) Pull(model string, ignoreRuntimeMemoryCheck bool, progress func(string)) (string, bool, error) {
+// authError marks a registry error caused by missing, invalid, or stale
+// credentials while keeping the runner's original message.
+type authError struct {
+	error
+}
+
+func (e authError) Unwrap() error {
+	return e.error
+}
+
+func (e authError) Is(target error) bool {
+	return target == ErrUnauthorized
+}
+
+// authFailureMarkers are fragments of the registry errors reported by the
+// model runner when it can't authenticate.
+var authFailureMarkers = []string{
+	"unauthorized",
+	"authentication required",
+	"403 forbidden",
+	"denied: ",
+	"access to the resource is denied",
+}
+
+// asAuthError wraps err in an authError if statusCode or message indicates a
+// registry authentication failure.
+func asAuthError(err error, statusCode int, message string) error {
+	if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		return authError{err}
+	}
+	message = strings.ToLower(message)
+	for _, marker := range authFailureMarkers {
+		if strings.Contains(message, marker) {
+			return authError{err}
+		}
+	}
+	return err
+}
+
+func (c *Client) Pull(ctx context.Context, model string, ignoreRuntimeMemoryCheck bool, progress func(string)) (string, bool, error) {
+	response, progressShown, _, err := c.PullWithSummary(ctx, model, ignoreRuntimeMemoryCheck, DefaultPullRetries, func(p PullProgress) {
+		progress(p.Message)
+	})
+	return response, progressShown, err
+}
+
+// PullProgress is an update on the progress of a pull.
+type PullProgress struct {
+	// Message describes the progress for display.
+	Message string
+	// Current is the number of bytes downloaded so far, out of Total.
+	Current uint64
+	Total   uint64
+	// Retry is set on the update announcing that the pull is retried after
+	// a failed attempt. The progress starts over after it.
+	Retry bool
+}
+
+// PullSummary describes the amount of data transferred by a pull.
+type PullSummary struct {
+	// Total is the size of the model.
+	Total uint64
+	// Downloaded is the number of bytes downloaded, which excludes the layers
+	// that were already present locally.
+	Downloaded uint64
+	// Layers is the number of layers downloaded.
+	Layers int
+}
+
+// DefaultPullRetries is the number of times Pull retries a pull that failed
+// because of a dropped connection or an unavailable server.
+const DefaultPullRetries = 3
+
+// pullRetryDelay is the delay before the first retry of a pull. It doubles
+// with each retry.
+var pullRetryDelay = time.Second
+
+// retryablePullError wraps the errors of pull attempts that may succeed if
+// retried.
+type retryablePullError struct {
+	error
+}
+
+func (e retryablePullError) Unwrap() error {
+	return e.error
+}
+
+// PullWithSummary is like Pull but reports structured progress updates and
+// also returns a summary of the data transferred once the pull has succeeded.
+// A pull that fails because of a dropped connection or an unavailable server
+// or gateway is retried up to retries times with exponential backoff. The model runner
+// starts each attempt over, but it keeps the layers that it has completely
+// downloaded.
+func (c *Client) PullWithSummary(ctx context.Context, model string, ignoreRuntimeMemoryCheck bool, retries int, progress func(PullProgress)) (string, bool, PullSummary, error) {
 	model = normalizeHuggingFaceModelName(model)
+	progressShown := false
+	for attempt := 0; ; attempt++ {
+		response, shown, summary, err := c.pullOnce(ctx, model, ignoreRuntimeMemoryCheck, progress)
+		progressShown = progressShown || shown
+		var retryable retryablePullError
+		if !errors.As(err, &retryable) {
+			return response, progressShown, summary, err
+		}
+		if attempt >= retries || ctx.Err() != nil {
+			return response, progressShown, summary, retryable.error
+		}
+		delay := pullRetryDelay << attempt
+		progress(PullProgress{
+			Message: fmt.Sprintf("%v; retrying in %s (retry %d of %d)", retryable.error, delay, attempt+1, retries),
+			Retry:   true,
+		})
+		select {
+		case <-ctx.Done():
+			return "", progressShown, PullSummary{}, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isRetryablePullStatus reports whether a pull that failed with the given
+// status code may succeed if retried. Other server errors, such as the
+// runner's memory check failing or an invalid reference, fail the same way
+// every time.
+func isRetryablePullStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// pullOnce makes a single attempt at pulling a model. Errors after which the
+// pull may succeed if retried are wrapped in a retryablePullError.
+func (c *Client) pullOnce(ctx context.Context, model string, ignoreRuntimeMemoryCheck bool, progress func(PullProgress)) (string, bool, PullSummary, error) {
 	jsonData, err := json.Marshal(dmrm.ModelCreateRequest{From: model, IgnoreRuntimeMemoryCheck: ignoreRuntimeMemoryCheck})
 	if err != nil {
-		return "", false, fmt.Errorf("error marshaling request: %w", err)
+		return "", false, PullSummary{}, fmt.Errorf("error marshaling request: %w", err)
 	}
 
 	createPath := inference.ModelsPrefix + "/create"
-	resp, err := c.doRequest(
+	resp, err := c.doRequestContext(
+		ctx,
 		http.MethodPost,
 		createPath,
 		bytes.NewReader(jsonData),
 	)
 	if err != nil {
-		return "", false, c.handleQueryError(err, createPath)
+		err = c.handleQueryError(err, createPath)
+		if ctx.Err() == nil && isConnectionDropped(err) {
+			err = retryablePullError{err}
+		}
+		return "", false, PullSummary{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", false, fmt.Errorf("pulling %s failed with status %s: %s", model, resp.Status, string(body))
+		err := fmt.Errorf("pulling %s failed with status %s: %s", model, resp.Status, string(body))
+		if isRetryablePullStatus(resp.StatusCode) {
+			return "", false, PullSummary{}, retryablePullError{err}
+		}
+		return "", false, PullSummary{}, asAuthError(err, resp.StatusCode, string(body))
+	}
+	if err := checkContentType(resp, nil); err != nil {
+		return "", false, PullSummary{}, err
 	}
 
 	progressShown := false
 	current := uint64(0)                     // Track cumulative progress across all layers
+	total := uint64(0)                       // Track the size of the model
 	layerProgress := make(map[string]uint64) // Track progress per layer ID
+	layerSizes := make(map[string]uint64)    // Track the size of each downloaded layer
 
 	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
@@ -144,7 +307,7 @@ func (c *Client) Pull(model string, ignoreRuntimeMemoryCheck bool, progress func
 		// Parse the progress message
 		var progressMsg ProgressMessage
 		if err := json.Unmarshal([]byte(html.UnescapeString(progressLine)), &progressMsg); err != nil {
-			return "", progressShown, fmt.Errorf("error parsing progress message: %w", err)
+			return "", progressShown, PullSummary{}, fmt.Errorf("error parsing progress message: %w", err)
 		}
 
 		// Handle different message types
@@ -153,6 +316,8 @@ func (c *Client) Pull(model string, ignoreRuntimeMemoryCheck bool, progress func
 			// Update the current progress for this layer
 			layerID := progressMsg.Layer.ID
 			layerProgress[layerID] = progressMsg.Layer.Current
+			layerSizes[layerID] = progressMsg.Layer.Size
+			total = progressMsg.Total
 
 			// Sum all layer progress values
 			current = uint64(0)
@@ -160,25 +325,63 @@ func (c *Client) Pull(model string, ignoreRuntimeMemoryCheck bool, progress func
 				current += layerCurrent
 			}
 
-			progress(fmt.Sprintf("Downloaded %s of %s", units.CustomSize("%.2f%s", float64(current), 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"}), units.CustomSize("%.2f%s", float64(progressMsg.Total), 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"})))
+			progress(PullProgress{
+				Message: fmt.Sprintf("Downloaded %s of %s", formatSize(current), formatSize(progressMsg.Total)),
+				Current: current,
+				Total:   total,
+			})
 			progressShown = true
 		case "error":
-			return "", progressShown, fmt.Errorf("error pulling model: %s", progressMsg.Message)
+			err := fmt.Errorf("error pulling model: %s", progressMsg.Message)
+			return "", progressShown, PullSummary{}, asAuthError(err, 0, progressMsg.Message)
 		case "success":
-			return progressMsg.Message, progressShown, nil
+			// The runner keeps the layers it has completely downloaded, so a
+			// pull that was interrupted earlier only downloads the others.
+			// They don't report any progress, which would otherwise leave the
+			// progress short of the total.
+			downloaded := uint64(0)
+			for _, size := range layerSizes {
+				downloaded += size
+			}
+			if progressShown && total > downloaded {
+				progress(PullProgress{
+					Message: fmt.Sprintf("Downloaded %s of %s (%s already present locally)", formatSize(current), formatSize(total), formatSize(total-downloaded)),
+					Current: current,
+					Total:   total,
+				})
+			}
+			return progressMsg.Message, progressShown, PullSummary{Total: total, Downloaded: current, Layers: len(layerSizes)}, nil
 		default:
-			return "", progressShown, fmt.Errorf("unknown message type: %s", progressMsg.Type)
+			return "", progressShown, PullSummary{}, fmt.Errorf("unknown message type: %s", progressMsg.Type)
 		}
 	}
 
 	// If we get here, something went wrong
-	return "", progressShown, fmt.Errorf("unexpected end of stream while pulling model %s", model)
+	if err := ctx.Err(); err != nil {
+		return "", progressShown, PullSummary{}, err
+	}
+	if err := scanner.Err(); err != nil {
+		return "", progressShown, PullSummary{}, retryablePullError{fmt.Errorf("error reading progress while pulling model %s: %w", model, err)}
+	}
+	return "", progressShown, PullSummary{}, retryablePullError{fmt.Errorf("unexpected end of stream while pulling model %s", model)}
 }
 
-func (c *Client) Push(model string, progress func(string)) (string, bool, error) {
+// isConnectionDropped reports whether err is caused by the connection to the
+// model runner being reset or closed early.
+func isConnectionDropped(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// formatSize formats a size in bytes using decimal units.
+func formatSize(size uint64) string {
+	return units.CustomSize("%.2f%s", float64(size), 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"})
+}
+
+func (c *Client) Push(ctx context.Context, model string, progress func(string)) (string, bool, error) {
 	model = normalizeHuggingFaceModelName(model)
 	pushPath := inference.ModelsPrefix + "/" + model + "/push"
-	resp, err := c.doRequest(
+	resp, err := c.doRequestContext(
+		ctx,
 		http.MethodPost,
 		pushPath,
 		nil, // Assuming no body is needed for the push request
@@ -192,6 +395,9 @@ func (c *Client) Push(model string, progress func(string)) (string, bool, error)
 		body, _ := io.ReadAll(resp.Body)
 		return "", false, fmt.Errorf("pushing %s failed with status %s: %s", model, resp.Status, string(body))
 	}
+	if err := checkContentType(resp, nil); err != nil {
+		return "", false, err
+	}
 
 	progressShown := false
 
@@ -223,17 +429,20 @@ func (c *Client) Push(model string, progress func(string)) (string, bool, error)
 	}
 
 	// If we get here, something went wrong
+	if err := ctx.Err(); err != nil {
+		return "", progressShown, err
+	}
 	return "", progressShown, fmt.Errorf("unexpected end of stream while pushing model %s", model)
 }
 
-func (c *Client) List() ([]dmrm.Model, error) {
+func (c *Client) List() ([]Model, error) {
 	modelsRoute := inference.ModelsPrefix
 	body, err := c.listRaw(modelsRoute, "")
 	if err != nil {
-		return []dmrm.Model{}, err
+		return []Model{}, err
 	}
 
-	var modelsJson []dmrm.Model
+	var modelsJson []Model
 	if err := json.Unmarshal(body, &modelsJson); err != nil {
 		return modelsJson, fmt.Errorf("failed to unmarshal response body: %w", err)
 	}
@@ -248,7 +457,7 @@ func (c *Client) ListOpenAI(backend, apiKey string) (dmrm.OpenAIModelList, error
 	modelsRoute := fmt.Sprintf("%s/%s/v1/models", inference.InferencePrefix, backend)
 
 	// Use doRequestWithAuth to support API key authentication
-	resp, err := c.doRequestWithAuth(http.MethodGet, modelsRoute, nil, "openai", apiKey)
+	resp, err := c.doRequestWithAuth(context.Background(), http.MethodGet, modelsRoute, nil, "openai", apiKey)
 	if err != nil {
 		return dmrm.OpenAIModelList{}, c.handleQueryError(err, modelsRoute)
 	}
@@ -258,9 +467,9 @@ func (c *Client) ListOpenAI(backend, apiKey string) (dmrm.OpenAIModelList, error
 		return dmrm.OpenAIModelList{}, fmt.Errorf("failed to list models: %s", resp.Status)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readJSONBody(resp)
 	if err != nil {
-		return dmrm.OpenAIModelList{}, fmt.Errorf("failed to read response body: %w", err)
+		return dmrm.OpenAIModelList{}, err
 	}
 
 	var modelsJson dmrm.OpenAIModelList
@@ -270,49 +479,60 @@ func (c *Client) ListOpenAI(backend, apiKey string) (dmrm.OpenAIModelList, error
 	return modelsJson, nil
 }
 
-func (c *Client) Inspect(model string, remote bool) (dmrm.Model, error) {
+func (c *Client) Inspect(model string, remote bool) (Model, error) {
+	rawResponse, err := c.InspectRaw(model, remote)
+	if err != nil {
+		return Model{}, err
+	}
+	var modelInspect Model
+	if err := json.Unmarshal(rawResponse, &modelInspect); err != nil {
+		return modelInspect, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	return modelInspect, nil
+}
+
+// InspectRaw returns the model runner's response to an inspect request as
+// is, including any fields that Model doesn't know about.
+func (c *Client) InspectRaw(model string, remote bool) ([]byte, error) {
 	model = normalizeHuggingFaceModelName(model)
 	if model != "" {
 		if !strings.Contains(strings.Trim(model, "/"), "/") {
 			// Do an extra API call to check if the model parameter isn't a model ID.
 			modelId, err := c.fullModelID(model)
 			if err != nil {
-				return dmrm.Model{}, fmt.Errorf("invalid model name: %s", model)
+				return nil, fmt.Errorf("invalid model name: %s", model)
 			}
 			model = modelId
 		}
 	}
-	rawResponse, err := c.listRawWithQuery(fmt.Sprintf("%s/%s", inference.ModelsPrefix, model), model, remote)
+	return c.listRawWithQuery(fmt.Sprintf("%s/%s", inference.ModelsPrefix, model), model, remote)
+}
+
+func (c *Client) InspectOpenAI(model string) (dmrm.OpenAIModel, error) {
+	rawResponse, err := c.InspectOpenAIRaw(model)
 	if err != nil {
-		return dmrm.Model{}, err
+		return dmrm.OpenAIModel{}, err
 	}
-	var modelInspect dmrm.Model
+	var modelInspect dmrm.OpenAIModel
 	if err := json.Unmarshal(rawResponse, &modelInspect); err != nil {
 		return modelInspect, fmt.Errorf("failed to unmarshal response body: %w", err)
 	}
-
 	return modelInspect, nil
 }
 
-func (c *Client) InspectOpenAI(model string) (dmrm.OpenAIModel, error) {
+// InspectOpenAIRaw is like InspectRaw but uses the OpenAI models endpoint.
+func (c *Client) InspectOpenAIRaw(model string) ([]byte, error) {
 	model = normalizeHuggingFaceModelName(model)
 	modelsRoute := inference.InferencePrefix + "/v1/models"
 	if !strings.Contains(strings.Trim(model, "/"), "/") {
 		// Do an extra API call to check if the model parameter isn't a model ID.
 		var err error
 		if model, err = c.fullModelID(model); err != nil {
-			return dmrm.OpenAIModel{}, fmt.Errorf("invalid model name: %s", model)
+			return nil, fmt.Errorf("invalid model name: %s", model)
 		}
 	}
-	rawResponse, err := c.listRaw(fmt.Sprintf("%s/%s", modelsRoute, model), model)
-	if err != nil {
-		return dmrm.OpenAIModel{}, err
-	}
-	var modelInspect dmrm.OpenAIModel
-	if err := json.Unmarshal(rawResponse, &modelInspect); err != nil {
-		return modelInspect, fmt.Errorf("failed to unmarshal response body: %w", err)
-	}
-	return modelInspect, nil
+	return c.listRaw(fmt.Sprintf("%s/%s", modelsRoute, model), model)
 }
 
 func (c *Client) listRaw(route string, model string) ([]byte, error) {
@@ -337,11 +557,7 @@ func (c *Client) listRawWithQuery(route string, model string, remote bool) ([]by
 		return nil, fmt.Errorf("failed to list models: %s", resp.Status)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-	return body, nil
+	return readJSONBody(resp)
 }
 
 func (c *Client) fullModelID(id string) (string, error) {
@@ -364,8 +580,221 @@ func (c *Client) fullModelID(id string) (string, error) {
 	return "", fmt.Errorf("model with ID %s not found", id)
 }
 
-// Chat performs a chat request and streams the response content with selective markdown rendering.
-func (c *Client) Chat(backend, model, prompt, apiKey string, outputFunc func(string), shouldUseMarkdown bool) error {
+// chatMessages returns the messages of a chat request: the system prompt, if
+// any, the conversation so far, and the prompt.
+func chatMessages(opts ChatOptions, prompt string) []OpenAIChatMessage {
+	var messages []OpenAIChatMessage
+	if opts.System != "" {
+		messages = append(messages, OpenAIChatMessage{Role: "system", Content: opts.System})
+	}
+	messages = append(messages, opts.History...)
+	return append(messages, OpenAIChatMessage{Role: "user", Content: prompt})
+}
+
+// Chat performs a chat request and streams the response to onDelta as it is
+// generated. It returns the full response content (excluding any reasoning
+// content).
+func (c *Client) Chat(ctx context.Context, backend, model, prompt, apiKey string, opts ChatOptions, onDelta func(ChatDelta)) (string, error) {
+	// Canceling the request is the only way to stop a backend that keeps
+	// generating after StopAfterTokens.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reqBody := OpenAIChatRequest{
+		Model:           c.inferenceModel(model),
+		Messages:        chatMessages(opts, prompt),
+		Stream:          true,
+		SamplingOptions: opts.Sampling,
+	}
+	if opts.Think != nil {
+		reqBody.ChatTemplateKwargs = thinkingKwargs(opts.Params, *opts.Think)
+	}
+	if opts.Logprobs != nil {
+		reqBody.Logprobs = true
+		reqBody.TopLogprobs = opts.Logprobs
+	}
+
+	tokens := 0
+	truncated := false
+	hideReasoning := opts.Think != nil && !*opts.Think
+	var response strings.Builder
+	var finalUsage *ChatUsage
+
+	opts.Timing.begin(time.Now())
+	err := c.streamInference(ctx, backend, "/v1/chat/completions", apiKey, reqBody, opts.Params, func(data []byte) (bool, error) {
+		var streamResp OpenAIChatResponse
+		if err := json.Unmarshal(data, &streamResp); err != nil {
+			return false, fmt.Errorf("error parsing stream response: %w", err)
+		}
+
+		if streamResp.Usage != nil {
+			finalUsage = streamResp.Usage
+		}
+
+		if len(streamResp.Choices) > 0 {
+			// Models that ignore a request to disable thinking may still
+			// send reasoning content; it isn't shown in that case.
+			if streamResp.Choices[0].Delta.ReasoningContent != "" && !hideReasoning {
+				onDelta(ChatDelta{Reasoning: streamResp.Choices[0].Delta.ReasoningContent})
+			}
+			if streamResp.Choices[0].Delta.Content != "" {
+				chunk := streamResp.Choices[0].Delta.Content
+				response.WriteString(chunk)
+				onDelta(ChatDelta{Content: chunk})
+			}
+			if logprobs := streamResp.Choices[0].Logprobs; logprobs != nil && len(logprobs.Content) > 0 && opts.OnLogprobs != nil {
+				opts.OnLogprobs(logprobs.Content)
+			}
+			// Each delta usually holds a single token.
+			if streamResp.Choices[0].Delta.Content != "" || streamResp.Choices[0].Delta.ReasoningContent != "" {
+				tokens++
+				opts.Timing.token(time.Now())
+			}
+			if opts.StopAfterTokens > 0 && tokens >= opts.StopAfterTokens {
+				truncated = true
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	opts.Timing.end(time.Now())
+	if truncated {
+		cancel()
+		return response.String(), ErrTruncated
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if finalUsage != nil {
+		onDelta(ChatDelta{Usage: finalUsage})
+	}
+
+	return response.String(), nil
+}
+
+// Complete performs a raw completion request, bypassing chat templating, and
+// streams the generated text. It returns the full generated text.
+func (c *Client) Complete(ctx context.Context, backend, model, prompt, apiKey string, opts ChatOptions, outputFunc func(string)) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	request := OpenAICompletionRequest{
+		Model:           c.inferenceModel(model),
+		Prompt:          prompt,
+		Stream:          true,
+		SamplingOptions: opts.Sampling,
+		Logprobs:        opts.Logprobs,
+	}
+
+	var response strings.Builder
+	tokens := 0
+	truncated := false
+	opts.Timing.begin(time.Now())
+	err := c.streamInference(ctx, backend, "/v1/completions", apiKey, request, opts.Params, func(data []byte) (bool, error) {
+		var streamResp OpenAICompletionResponse
+		if err := json.Unmarshal(data, &streamResp); err != nil {
+			return false, fmt.Errorf("error parsing stream response: %w", err)
+		}
+		if len(streamResp.Choices) > 0 && streamResp.Choices[0].Text != "" {
+			response.WriteString(streamResp.Choices[0].Text)
+			outputFunc(streamResp.Choices[0].Text)
+			tokens++
+			opts.Timing.token(time.Now())
+		}
+		if len(streamResp.Choices) > 0 && streamResp.Choices[0].Logprobs != nil && opts.OnLogprobs != nil {
+			if logprobs := streamResp.Choices[0].Logprobs.tokens(); len(logprobs) > 0 {
+				opts.OnLogprobs(logprobs)
+			}
+		}
+		if opts.StopAfterTokens > 0 && tokens >= opts.StopAfterTokens {
+			truncated = true
+			return false, nil
+		}
+		return true, nil
+	})
+	opts.Timing.end(time.Now())
+	if truncated {
+		cancel()
+		return response.String(), ErrTruncated
+	}
+	if err != nil {
+		return "", err
+	}
+	return response.String(), nil
+}
+
+// Sample generates n responses to a prompt in a single request, using the
+// completions endpoint if raw is set and the chat completions endpoint
+// otherwise. The responses are streamed interleaved, so they're only returned
+// once all of them are complete. Reasoning content is discarded.
+func (c *Client) Sample(ctx context.Context, backend, model, prompt, apiKey string, n int, opts ChatOptions, raw bool) ([]string, error) {
+	model = c.inferenceModel(model)
+
+	var request any
+	endpoint := "/v1/chat/completions"
+	if raw {
+		request = OpenAICompletionRequest{Model: model, Prompt: prompt, Stream: true, SamplingOptions: opts.Sampling, N: n}
+		endpoint = "/v1/completions"
+	} else {
+		chatRequest := OpenAIChatRequest{
+			Model:           model,
+			Messages:        chatMessages(opts, prompt),
+			Stream:          true,
+			SamplingOptions: opts.Sampling,
+			N:               n,
+		}
+		if opts.Think != nil {
+			chatRequest.ChatTemplateKwargs = thinkingKwargs(opts.Params, *opts.Think)
+		}
+		request = chatRequest
+	}
+
+	responses := make([]strings.Builder, n)
+	appendChunk := func(index int, chunk string) {
+		// Ignore choices that weren't requested rather than failing.
+		if index >= 0 && index < n {
+			responses[index].WriteString(chunk)
+		}
+	}
+	err := c.streamInference(ctx, backend, endpoint, apiKey, request, opts.Params, func(data []byte) (bool, error) {
+		if raw {
+			var streamResp OpenAICompletionResponse
+			if err := json.Unmarshal(data, &streamResp); err != nil {
+				return false, fmt.Errorf("error parsing stream response: %w", err)
+			}
+			for _, choice := range streamResp.Choices {
+				appendChunk(choice.Index, choice.Text)
+			}
+		} else {
+			var streamResp OpenAIChatResponse
+			if err := json.Unmarshal(data, &streamResp); err != nil {
+				return false, fmt.Errorf("error parsing stream response: %w", err)
+			}
+			for _, choice := range streamResp.Choices {
+				appendChunk(choice.Index, choice.Delta.Content)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make([]string, n)
+	for i := range responses {
+		choices[i] = responses[i].String()
+	}
+	// Backends that don't support n only return the first choice.
+	for len(choices) > 1 && choices[len(choices)-1] == "" {
+		choices = choices[:len(choices)-1]
+	}
+	return choices, nil
+}
+
+// inferenceModel returns the model to send in an inference request for
+// model, expanding it if it's a short model ID.
+func (c *Client) inferenceModel(model string) string {
 	model = normalizeHuggingFaceModelName(model)
 	if !strings.Contains(strings.Trim(model, "/"), "/") {
 		// Do an extra API call to check if the model parameter isn't a model ID.
@@ -373,31 +802,28 @@ func (c *Client) Chat(backend, model, prompt, apiKey string, outputFunc func(str
 			model = expanded
 		}
 	}
+	return model
+}
 
-	reqBody := OpenAIChatRequest{
-		Model: model,
-		Messages: []OpenAIChatMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: true,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
+// streamInference sends a streaming inference request to an OpenAI-compatible
+// endpoint of backend, such as "/v1/chat/completions", and passes the data of
+// each server-sent event of the response to onData. It stops reading the
+// response early if onData returns false or an error.
+func (c *Client) streamInference(ctx context.Context, backend, endpoint, apiKey string, request any, params map[string]any, onData func(data []byte) (bool, error)) error {
+	jsonData, err := marshalWithParams(request, params)
 	if err != nil {
 		return fmt.Errorf("error marshaling request: %w", err)
 	}
 
 	var completionsPath string
 	if backend != "" {
-		completionsPath = inference.InferencePrefix + "/" + backend + "/v1/chat/completions"
+		completionsPath = inference.InferencePrefix + "/" + backend + endpoint
 	} else {
-		completionsPath = inference.InferencePrefix + "/v1/chat/completions"
+		completionsPath = inference.InferencePrefix + endpoint
 	}
 
 	resp, err := c.doRequestWithAuth(
+		ctx,
 		http.MethodPost,
 		completionsPath,
 		bytes.NewReader(jsonData),
@@ -413,152 +839,145 @@ func (c *Client) Chat(backend, model, prompt, apiKey string, outputFunc func(str
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("error response: status=%d body=%s", resp.StatusCode, body)
 	}
-
-	type chatPrinterState int
-	const (
-		chatPrinterNone chatPrinterState = iota
-		chatPrinterReasoning
-		chatPrinterContent
-	)
-
-	printerState := chatPrinterNone
-	reasoningFmt := color.New().Add(color.Italic)
-
-	var finalUsage *struct {
-		CompletionTokens int `json:"completion_tokens"`
-		PromptTokens     int `json:"prompt_tokens"`
-		TotalTokens      int `json:"total_tokens"`
+	if err := checkContentType(resp, nil); err != nil {
+		return err
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
-		if !strings.HasPrefix(line, "data: ") {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
 			continue
 		}
-
-		data := strings.TrimPrefix(line, "data: ")
-
 		if data == "[DONE]" {
 			break
 		}
-
-		var streamResp OpenAIChatResponse
-		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
-			return fmt.Errorf("error parsing stream response: %w", err)
-		}
-
-		if streamResp.Usage != nil {
-			finalUsage = streamResp.Usage
-		}
-
-		if len(streamResp.Choices) > 0 {
-			if streamResp.Choices[0].Delta.ReasoningContent != "" {
-				chunk := streamResp.Choices[0].Delta.ReasoningContent
-				if printerState == chatPrinterContent {
-					outputFunc("\n\n")
-				}
-				if printerState != chatPrinterReasoning {
-					const thinkingHeader = "Thinking:\n"
-					if reasoningFmt != nil {
-						reasoningFmt.Print(thinkingHeader)
-					} else {
-						outputFunc(thinkingHeader)
-					}
-				}
-				printerState = chatPrinterReasoning
-				if reasoningFmt != nil {
-					reasoningFmt.Print(chunk)
-				} else {
-					outputFunc(chunk)
-				}
-			}
-			if streamResp.Choices[0].Delta.Content != "" {
-				chunk := streamResp.Choices[0].Delta.Content
-				if printerState == chatPrinterReasoning {
-					outputFunc("\n\n--\n\n")
-				}
-				printerState = chatPrinterContent
-				outputFunc(chunk)
-			}
+		if more, err := onData([]byte(data)); err != nil || !more {
+			return err
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := scanner.Err(); err != nil {
 		return fmt.Errorf("error reading response stream: %w", err)
 	}
+	return nil
+}
 
-	if finalUsage != nil {
-		usageInfo := fmt.Sprintf("\n\nToken usage: %d prompt + %d completion = %d total",
-			finalUsage.PromptTokens,
-			finalUsage.CompletionTokens,
-			finalUsage.TotalTokens)
+// thinkingKwargs returns the chat template arguments that toggle a model's
+// thinking mode, preserving any chat_template_kwargs object set through
+// params, which would otherwise be dropped in favor of the client's field.
+func thinkingKwargs(params map[string]any, think bool) map[string]any {
+	kwargs := map[string]any{}
+	switch existing := params["chat_template_kwargs"].(type) {
+	case map[string]any:
+		maps.Copy(kwargs, existing)
+	case json.RawMessage:
+		_ = json.Unmarshal(existing, &kwargs)
+	}
+	kwargs["enable_thinking"] = think
+	return kwargs
+}
 
-		usageFmt := color.New(color.FgHiBlack)
-		if !shouldUseMarkdown {
-			usageFmt.DisableColor()
+// marshalWithParams marshals request and merges params into the resulting JSON
+// object. Fields already present in request take precedence over params.
+func marshalWithParams(request any, params map[string]any) ([]byte, error) {
+	data, err := json.Marshal(request)
+	if err != nil || len(params) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range params {
+		if _, ok := fields[key]; ok {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parameter %q: %w", key, err)
 		}
-		outputFunc(usageFmt.Sprint(usageInfo))
+		fields[key] = encoded
 	}
-
-	return nil
+	return json.Marshal(fields)
 }
 
+// DefaultRemoveParallel is the number of models Remove deletes concurrently.
+const DefaultRemoveParallel = 4
+
 func (c *Client) Remove(models []string, force bool) (string, error) {
-	modelRemoved := ""
-	for _, model := range models {
-		model = normalizeHuggingFaceModelName(model)
-		// Check if not a model ID passed as parameter.
-		if !strings.Contains(model, "/") {
-			if expanded, err := c.fullModelID(model); err == nil {
-				model = expanded
-			}
-		}
+	return c.RemoveParallel(models, force, DefaultRemoveParallel)
+}
 
-		// Construct the URL with query parameters
-		removePath := fmt.Sprintf("%s/%s?force=%s",
-			inference.ModelsPrefix,
-			model,
-			strconv.FormatBool(force),
-		)
+// RemoveParallel removes models, deleting up to parallel of them at a time.
+// A failure to remove a model doesn't prevent removing the others. The output
+// of each model is returned in the order of models, along with the errors of
+// all the models that failed.
+func (c *Client) RemoveParallel(models []string, force bool, parallel int) (string, error) {
+	outputs := make([]string, len(models))
+	errs := make([]error, len(models))
+	var g errgroup.Group
+	g.SetLimit(max(parallel, 1))
+	for i, model := range models {
+		g.Go(func() error {
+			outputs[i], errs[i] = c.removeModel(model, force)
+			return nil
+		})
+	}
+	g.Wait()
+	return strings.Join(outputs, ""), stderrors.Join(errs...)
+}
 
-		resp, err := c.doRequest(http.MethodDelete, removePath, nil)
-		if err != nil {
-			return modelRemoved, c.handleQueryError(err, removePath)
+func (c *Client) removeModel(model string, force bool) (string, error) {
+	model = normalizeHuggingFaceModelName(model)
+	// Check if not a model ID passed as parameter.
+	if !strings.Contains(model, "/") {
+		if expanded, err := c.fullModelID(model); err == nil {
+			model = expanded
 		}
-		defer resp.Body.Close()
+	}
 
-		var bodyStr string
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			bodyStr = fmt.Sprintf("(failed to read response body: %v)", err)
-		} else {
-			bodyStr = string(body)
-		}
+	// Construct the URL with query parameters
+	removePath := fmt.Sprintf("%s/%s?force=%s",
+		inference.ModelsPrefix,
+		model,
+		strconv.FormatBool(force),
+	)
 
-		if resp.StatusCode == http.StatusOK {
-			var deleteResponse distribution.DeleteModelResponse
-			if err := json.Unmarshal(body, &deleteResponse); err != nil {
-				modelRemoved += fmt.Sprintf("Model %s removed successfully, but failed to parse response: %v\n", model, err)
-			} else {
-				for _, msg := range deleteResponse {
-					if msg.Untagged != nil {
-						modelRemoved += fmt.Sprintf("Untagged: %s\n", *msg.Untagged)
-					}
-					if msg.Deleted != nil {
-						modelRemoved += fmt.Sprintf("Deleted: %s\n", *msg.Deleted)
-					}
-				}
-			}
-		} else {
-			if resp.StatusCode == http.StatusNotFound {
-				return modelRemoved, fmt.Errorf("no such model: %s", model)
-			}
-			return modelRemoved, fmt.Errorf("removing %s failed with status %s: %s", model, resp.Status, bodyStr)
+	resp, err := c.doRequest(http.MethodDelete, removePath, nil)
+	if err != nil {
+		return "", c.handleQueryError(err, removePath)
+	}
+	defer resp.Body.Close()
+
+	var bodyStr string
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		bodyStr = fmt.Sprintf("(failed to read response body: %v)", err)
+	} else {
+		bodyStr = string(body)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("no such model: %s", model)
+		}
+		return "", fmt.Errorf("removing %s failed with status %s: %s", model, resp.Status, bodyStr)
+	}
+	var deleteResponse distribution.DeleteModelResponse
+	if err := json.Unmarshal(body, &deleteResponse); err != nil {
+		return fmt.Sprintf("Model %s removed successfully, but failed to parse response: %v\n", model, err), nil
+	}
+	var modelRemoved string
+	for _, msg := range deleteResponse {
+		if msg.Untagged != nil {
+			modelRemoved += fmt.Sprintf("Untagged: %s\n", *msg.Untagged)
+		}
+		if msg.Deleted != nil {
+			modelRemoved += fmt.Sprintf("Deleted: %s\n", *msg.Deleted)
 		}
 	}
 	return modelRemoved, nil
@@ -574,6 +993,12 @@ type BackendStatus struct {
 	Mode string `json:"mode"`
 	// LastUsed represents when this backend was last used (if it's idle)
 	LastUsed time.Time `json:"last_used,omitempty"`
+	// ContextSize is the context size the model was loaded with, if reported
+	// by the runner.
+	ContextSize *uint64 `json:"context_size,omitempty"`
+	// MemoryUsage is the memory footprint of the loaded model in bytes, if
+	// reported by the runner.
+	MemoryUsage *uint64 `json:"memory_usage,omitempty"`
 }
 
 func (c *Client) PS() ([]BackendStatus, error) {
@@ -588,7 +1013,10 @@ func (c *Client) PS() ([]BackendStatus, error) {
 		return []BackendStatus{}, fmt.Errorf("failed to list running models: %s", resp.Status)
 	}
 
-	body, _ := io.ReadAll(resp.Body)
+	body, err := readJSONBody(resp)
+	if err != nil {
+		return []BackendStatus{}, err
+	}
 	var ps []BackendStatus
 	if err := json.Unmarshal(body, &ps); err != nil {
 		return []BackendStatus{}, fmt.Errorf("failed to unmarshal response body: %w", err)
@@ -615,7 +1043,10 @@ func (c *Client) DF() (DiskUsage, error) {
 		return DiskUsage{}, fmt.Errorf("failed to get disk usage: %s", resp.Status)
 	}
 
-	body, _ := io.ReadAll(resp.Body)
+	body, err := readJSONBody(resp)
+	if err != nil {
+		return DiskUsage{}, err
+	}
 	var df DiskUsage
 	if err := json.Unmarshal(body, &df); err != nil {
 		return DiskUsage{}, fmt.Errorf("failed to unmarshal response body: %w", err)
@@ -654,9 +1085,9 @@ func (c *Client) Unload(req UnloadRequest) (UnloadResponse, error) {
 		return UnloadResponse{}, fmt.Errorf("unloading failed with status %s: %s", resp.Status, string(body))
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readJSONBody(resp)
 	if err != nil {
-		return UnloadResponse{}, fmt.Errorf("failed to read response body: %w", err)
+		return UnloadResponse{}, err
 	}
 
 	var unloadResp UnloadResponse
@@ -750,12 +1181,18 @@ func (c *Client) Requests(modelFilter string, streaming bool, includeExisting bo
 
 // doRequest is a helper function that performs HTTP requests and handles 503 responses
 func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response, error) {
-	return c.doRequestWithAuth(method, path, body, "", "")
+	return c.doRequestContext(context.Background(), method, path, body)
+}
+
+// doRequestContext is like doRequest, but aborts the request when ctx is
+// cancelled.
+func (c *Client) doRequestContext(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	return c.doRequestWithAuth(ctx, method, path, body, "", "")
 }
 
 // doRequestWithAuth is a helper function that performs HTTP requests with optional authentication
-func (c *Client) doRequestWithAuth(method, path string, body io.Reader, backend, apiKey string) (*http.Response, error) {
-	req, err := http.NewRequest(method, c.modelRunner.URL(path), body)
+func (c *Client) doRequestWithAuth(ctx context.Context, method, path string, body io.Reader, backend, apiKey string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.modelRunner.URL(path), body)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -790,10 +1227,38 @@ func (c *Client) handleQueryError(err error, path string) error {
 	return fmt.Errorf("error querying %s: %w", path, err)
 }
 
+// readJSONBody reads the body of a response that should hold JSON.
+func readJSONBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := checkContentType(resp, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// checkContentType returns an error if resp holds something other than JSON
+// or a stream of JSON values, such as the HTML error page of a misconfigured
+// proxy in front of the model runner. body is the response body, or nil for
+// streams, which are only checked based on their content type.
+func checkContentType(resp *http.Response, body []byte) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	body = bytes.TrimSpace(body)
+	if mediaType != "text/html" && (len(body) == 0 || strings.IndexByte(`{["-0123456789tfn`, body[0]) >= 0) {
+		return nil
+	}
+	if mediaType == "" || strings.HasSuffix(mediaType, "json") {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+	return fmt.Errorf("expected JSON from runner but got %s; check MODEL_RUNNER_HOST", mediaType)
+}
+
 func (c *Client) Tag(source, targetRepo, targetTag string) error {
 	source = normalizeHuggingFaceModelName(source)
-	// Check if the source is a model ID, and expand it if necessary
-	if !strings.Contains(strings.Trim(source, "/"), "/") {
+	// Check if the source is a short model ID, and expand it if necessary
+	if !strings.Contains(strings.Trim(source, "/"), "/") && !strings.HasPrefix(source, "sha256:") {
 		// Do an extra API call to check if the model parameter might be a model ID
 		if expanded, err := c.fullModelID(source); err == nil {
 			source = expanded
@@ -825,6 +1290,33 @@ func (c *Client) Tag(source, targetRepo, targetTag string) error {
 	return nil
 }
 
+// SaveModel streams a stored model out of the model runner to w, as a tar
+// archive in the format accepted by LoadModel.
+func (c *Client) SaveModel(ctx context.Context, model string, w io.Writer) error {
+	model = normalizeHuggingFaceModelName(model)
+	savePath := inference.ModelsPrefix + "/" + model + "/save"
+	resp, err := c.doRequestContext(ctx, http.MethodPost, savePath, nil)
+	if err != nil {
+		return c.handleQueryError(err, savePath)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusNotFound {
+			if strings.Contains(string(body), "unknown action") {
+				return ErrSaveUnsupported
+			}
+			return errors.Wrap(ErrNotFound, model)
+		}
+		return fmt.Errorf("saving %s failed with status %s: %s", model, resp.Status, string(body))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read the archive of %s: %w", model, err)
+	}
+	return nil
+}
+
 func (c *Client) LoadModel(ctx context.Context, r io.Reader) error {
 	loadPath := fmt.Sprintf("%s/load", inference.ModelsPrefix)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.modelRunner.URL(loadPath), r)
@@ -2,10 +2,13 @@ package desktop
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	mockdesktop "github.com/docker/model-cli/mocks"
 	"github.com/docker/model-runner/pkg/inference/models"
@@ -36,7 +39,7 @@ func TestPullHuggingFaceModel(t *testing.T) {
 		Body:       io.NopCloser(bytes.NewBufferString(`{"type":"success","message":"Model pulled successfully"}`)),
 	}, nil)
 
-	_, _, err := client.Pull(modelName, false, func(s string) {})
+	_, _, err := client.Pull(context.Background(), modelName, false, func(s string) {})
 	assert.NoError(t, err)
 }
 
@@ -63,7 +66,7 @@ func TestChatHuggingFaceModel(t *testing.T) {
 		Body:       io.NopCloser(bytes.NewBufferString("data: {\"choices\":[{\"delta\":{\"content\":\"Hello there!\"}}]}\n")),
 	}, nil)
 
-	err := client.Chat("", modelName, prompt, "", func(s string) {}, false)
+	_, err := client.Chat(context.Background(), "", modelName, prompt, "", ChatOptions{}, func(ChatDelta) {})
 	assert.NoError(t, err)
 }
 
@@ -122,7 +125,7 @@ func TestNonHuggingFaceModel(t *testing.T) {
 		Body:       io.NopCloser(bytes.NewBufferString(`{"type":"success","message":"Model pulled successfully"}`)),
 	}, nil)
 
-	_, _, err := client.Pull(modelName, false, func(s string) {})
+	_, _, err := client.Pull(context.Background(), modelName, false, func(s string) {})
 	assert.NoError(t, err)
 }
 
@@ -145,7 +148,7 @@ func TestPushHuggingFaceModel(t *testing.T) {
 		Body:       io.NopCloser(bytes.NewBufferString(`{"type":"success","message":"Model pushed successfully"}`)),
 	}, nil)
 
-	_, _, err := client.Push(modelName, func(s string) {})
+	_, _, err := client.Push(context.Background(), modelName, func(s string) {})
 	assert.NoError(t, err)
 }
 
@@ -172,6 +175,32 @@ func TestRemoveHuggingFaceModel(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRemoveParallel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Times(3).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodDelete, req.Method)
+		repo := strings.TrimPrefix(req.URL.Path[strings.Index(req.URL.Path, "/ai/"):], "/")
+		if repo == "ai/missing" {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(""))}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`[{"Untagged":"` + repo + `:latest"}]`)),
+		}, nil
+	})
+
+	output, err := client.RemoveParallel([]string{"ai/first", "ai/missing", "ai/second"}, false, 2)
+	assert.Equal(t, "Untagged: ai/first:latest\nUntagged: ai/second:latest\n", output)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such model: ai/missing")
+}
+
 func TestTagHuggingFaceModel(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -224,3 +253,491 @@ func TestInspectOpenAIHuggingFaceModel(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedLowercase, model.ID)
 }
+
+func TestCompleteRaw(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	modelName := "ai/smollm2"
+	prompt := "Once upon a time"
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assert.True(t, strings.HasSuffix(req.URL.Path, "/v1/completions"))
+		var reqBody OpenAICompletionRequest
+		err := json.NewDecoder(req.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		assert.Equal(t, modelName, reqBody.Model)
+		assert.Equal(t, prompt, reqBody.Prompt)
+	}).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			"data: {\"choices\":[{\"text\":\" there\"}]}\n\n" +
+				"data: {\"choices\":[{\"text\":\" was\"}]}\n\n" +
+				"data: [DONE]\n")),
+	}, nil)
+
+	var output strings.Builder
+	response, err := client.Complete(context.Background(), "", modelName, prompt, "", ChatOptions{}, func(s string) { output.WriteString(s) })
+	assert.NoError(t, err)
+	assert.Equal(t, " there was", response)
+	assert.Equal(t, " there was", output.String())
+}
+
+func TestChatNoThink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		var reqBody OpenAIChatRequest
+		err := json.NewDecoder(req.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"enable_thinking": false, "custom": "value"}, reqBody.ChatTemplateKwargs)
+	}).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			"data: {\"choices\":[{\"delta\":{\"reasoning_content\":\"Hmm.\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hello!\"}}]}\n\n" +
+				"data: [DONE]\n")),
+	}, nil)
+
+	think := false
+	opts := ChatOptions{
+		Params: map[string]any{"chat_template_kwargs": json.RawMessage(`{"custom":"value"}`)},
+		Think:  &think,
+	}
+	var output strings.Builder
+	response, err := client.Chat(context.Background(), "", "ai/qwen3", "Hi", "", opts, func(d ChatDelta) { output.WriteString(d.Content) })
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello!", response)
+	assert.Equal(t, "Hello!", output.String())
+}
+
+func TestChatDeltas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			"data: {\"choices\":[{\"delta\":{\"reasoning_content\":\"Hmm.\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hello!\"}}]}\n\n" +
+				"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}\n\n" +
+				"data: [DONE]\n")),
+	}, nil)
+
+	var deltas []ChatDelta
+	response, err := client.Chat(context.Background(), "", "ai/qwen3", "Hi", "", ChatOptions{}, func(d ChatDelta) { deltas = append(deltas, d) })
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello!", response)
+	assert.Equal(t, []ChatDelta{
+		{Reasoning: "Hmm."},
+		{Content: "Hello!"},
+		{Usage: &ChatUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}},
+	}, deltas)
+}
+
+func TestChatSystem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		var reqBody OpenAIChatRequest
+		err := json.NewDecoder(req.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		assert.Equal(t, []OpenAIChatMessage{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Hi"},
+			{Role: "assistant", Content: "Hello!"},
+			{Role: "user", Content: "Bye"},
+		}, reqBody.Messages)
+	}).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString("data: {\"choices\":[{\"delta\":{\"content\":\"Bye!\"}}]}\n")),
+	}, nil)
+
+	opts := ChatOptions{
+		System:  "Be brief.",
+		History: []OpenAIChatMessage{{Role: "user", Content: "Hi"}, {Role: "assistant", Content: "Hello!"}},
+	}
+	_, err := client.Chat(context.Background(), "", "ai/smollm2", "Bye", "", opts, func(ChatDelta) {})
+	assert.NoError(t, err)
+}
+
+func TestChatSampling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		var reqBody map[string]any
+		err := json.NewDecoder(req.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		assert.Equal(t, float64(0), reqBody["temperature"])
+		assert.Equal(t, float64(16), reqBody["max_tokens"])
+		assert.NotContains(t, reqBody, "top_p")
+	}).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			"data: {\"choices\":[{\"delta\":{\"content\":\"Hello!\"}}]}\n\n" +
+				"data: [DONE]\n")),
+	}, nil)
+
+	temperature, maxTokens := 0.0, 16
+	opts := ChatOptions{Sampling: SamplingOptions{Temperature: &temperature, MaxTokens: &maxTokens}}
+	response, err := client.Chat(context.Background(), "", "ai/smollm2", "Hi", "", opts, func(ChatDelta) {})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello!", response)
+}
+
+func TestSaveModel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	gomock.InOrder(
+		mockClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.True(t, strings.HasSuffix(req.URL.Path, "/models/ai/smollm2/save"))
+		}).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString("archive")),
+		}, nil),
+		mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewBufferString("unknown action \"save\"\n")),
+		}, nil),
+	)
+
+	var archive bytes.Buffer
+	require.NoError(t, client.SaveModel(context.Background(), "ai/smollm2", &archive))
+	assert.Equal(t, "archive", archive.String())
+
+	err := client.SaveModel(context.Background(), "ai/smollm2", io.Discard)
+	assert.ErrorIs(t, err, ErrSaveUnsupported)
+}
+
+func TestPullUnauthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"type":"error","message":"GET https://registry.example.com/v2/: UNAUTHORIZED: authentication required"}`)),
+	}, nil)
+	mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"type":"error","message":"no space left on device"}`)),
+	}, nil)
+
+	_, _, err := client.Pull(context.Background(), "registry.example.com/private/model", false, func(s string) {})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Contains(t, err.Error(), "authentication required")
+
+	_, _, err = client.Pull(context.Background(), "registry.example.com/private/model", false, func(s string) {})
+	assert.NotErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestPullSkippedLayers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			`{"type":"progress","total":3000,"layer":{"ID":"sha256:b","Size":1000,"Current":1000}}` + "\n" +
+				`{"type":"success","message":"Model pulled successfully"}` + "\n")),
+	}, nil)
+
+	var lines []string
+	response, progressShown, err := client.Pull(context.Background(), "ai/smollm2", false, func(s string) { lines = append(lines, s) })
+	require.NoError(t, err)
+	assert.Equal(t, "Model pulled successfully", response)
+	assert.True(t, progressShown)
+	assert.Equal(t, []string{
+		"Downloaded 1.00kB of 3.00kB",
+		"Downloaded 1.00kB of 3.00kB (2.00kB already present locally)",
+	}, lines)
+}
+
+func TestPullRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	defer func(delay time.Duration) { pullRetryDelay = delay }(pullRetryDelay)
+	pullRetryDelay = 0
+
+	gomock.InOrder(
+		mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusBadGateway,
+			Status:     "502 Bad Gateway",
+			Body:       io.NopCloser(bytes.NewBufferString("upstream error")),
+		}, nil),
+		mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(
+				`{"type":"progress","total":3000,"layer":{"ID":"sha256:a","Size":3000,"Current":1000}}` + "\n")),
+		}, nil),
+		mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(
+				`{"type":"progress","total":3000,"layer":{"ID":"sha256:a","Size":3000,"Current":3000}}` + "\n" +
+					`{"type":"success","message":"Model pulled successfully"}` + "\n")),
+		}, nil),
+	)
+
+	var updates []PullProgress
+	response, progressShown, summary, err := client.PullWithSummary(context.Background(), "ai/smollm2", false, 3, func(p PullProgress) {
+		updates = append(updates, p)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Model pulled successfully", response)
+	assert.True(t, progressShown)
+	assert.Equal(t, uint64(3000), summary.Downloaded)
+	require.Len(t, updates, 4)
+	assert.True(t, updates[0].Retry)
+	assert.Contains(t, updates[0].Message, "502 Bad Gateway")
+	assert.Contains(t, updates[0].Message, "retry 1 of 3")
+	assert.Equal(t, uint64(1000), updates[1].Current)
+	assert.True(t, updates[2].Retry)
+	assert.Contains(t, updates[2].Message, "unexpected end of stream")
+	assert.Equal(t, uint64(3000), updates[3].Current)
+}
+
+func TestPullRetriesExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	defer func(delay time.Duration) { pullRetryDelay = delay }(pullRetryDelay)
+	pullRetryDelay = 0
+
+	mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusGatewayTimeout,
+			Status:     "504 Gateway Timeout",
+			Body:       io.NopCloser(bytes.NewBufferString("oops")),
+		}, nil
+	}).Times(2)
+
+	_, _, _, err := client.PullWithSummary(context.Background(), "ai/smollm2", false, 1, func(PullProgress) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "504 Gateway Timeout")
+}
+
+func TestPullNotRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	for _, status := range []int{http.StatusInternalServerError, http.StatusInsufficientStorage} {
+		mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Body:       io.NopCloser(bytes.NewBufferString("oops")),
+		}, nil)
+
+		var updates []PullProgress
+		_, _, _, err := client.PullWithSummary(context.Background(), "ai/smollm2", false, 3, func(p PullProgress) {
+			updates = append(updates, p)
+		})
+		require.Error(t, err)
+		assert.Empty(t, updates, "status %d", status)
+	}
+}
+
+func TestModelUnknownFields(t *testing.T) {
+	data := `{"id":"sha256:abc","tags":["ai/smollm2"],"created":1,"config":{"format":"gguf","chat_template":"{{ .Prompt }}"},"digest":"sha256:def"}`
+
+	var m Model
+	require.NoError(t, json.Unmarshal([]byte(data), &m))
+	assert.Equal(t, "sha256:abc", m.ID)
+	assert.Equal(t, []string{"config.chat_template", "digest"}, m.UnknownFields())
+
+	encoded, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, data, string(encoded))
+
+	m = Model{}
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"sha256:abc","tags":[],"created":1,"config":{}}`), &m))
+	assert.Empty(t, m.UnknownFields())
+}
+
+func TestCompleteLogprobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		var reqBody OpenAICompletionRequest
+		err := json.NewDecoder(req.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		require.NotNil(t, reqBody.Logprobs)
+		assert.Equal(t, 2, *reqBody.Logprobs)
+	}).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			"data: {\"choices\":[{\"text\":\" there\",\"logprobs\":{\"tokens\":[\" there\"],\"token_logprobs\":[-0.5],\"top_logprobs\":[{\" there\":-0.5,\" was\":-1.5}]}}]}\n\n" +
+				"data: {\"choices\":[{\"text\":\" was\",\"logprobs\":{\"content\":[{\"token\":\" was\",\"logprob\":-0.1}]}}]}\n\n" +
+				"data: [DONE]\n")),
+	}, nil)
+
+	var logprobs []TokenLogprob
+	top := 2
+	opts := ChatOptions{Logprobs: &top, OnLogprobs: func(l []TokenLogprob) { logprobs = append(logprobs, l...) }}
+	_, err := client.Complete(context.Background(), "", "ai/smollm2", "Once upon a time", "", opts, func(string) {})
+	assert.NoError(t, err)
+	assert.Equal(t, []TokenLogprob{
+		{Token: " there", Logprob: -0.5, TopLogprobs: []TopLogprob{{Token: " there", Logprob: -0.5}, {Token: " was", Logprob: -1.5}}},
+		{Token: " was", Logprob: -0.1},
+	}, logprobs)
+}
+
+func TestSampleInterleavedChoices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		var reqBody OpenAIChatRequest
+		err := json.NewDecoder(req.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		assert.Equal(t, 2, reqBody.N)
+	}).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			"data: {\"choices\":[{\"index\":1,\"delta\":{\"content\":\"Hi\"}}]}\n\n" +
+				"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello\"}}]}\n\n" +
+				"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\" there\"}},{\"index\":1,\"delta\":{\"content\":\"!\"}}]}\n\n" +
+				"data: [DONE]\n")),
+	}, nil)
+
+	choices, err := client.Sample(context.Background(), "", "ai/smollm2", "Greet me", "", 2, ChatOptions{}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hello there", "Hi!"}, choices)
+}
+
+func TestChatStopAfterTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+	mockContext := NewContextForMock(mockClient)
+	client := New(mockContext)
+
+	mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: io.NopCloser(bytes.NewBufferString(
+			"data: {\"choices\":[{\"delta\":{\"content\":\"One\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\" two\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\" three\"}}]}\n\n" +
+				"data: [DONE]\n")),
+	}, nil)
+
+	var output strings.Builder
+	response, err := client.Chat(context.Background(), "", "ai/smollm2", "Count", "", ChatOptions{StopAfterTokens: 2}, func(d ChatDelta) { output.WriteString(d.Content) })
+	assert.ErrorIs(t, err, ErrTruncated)
+	assert.Equal(t, "One two", response)
+	assert.Equal(t, "One two", output.String())
+}
+
+func TestResponseTiming(t *testing.T) {
+	start := time.Unix(1000, 0)
+	var timing ResponseTiming
+	timing.begin(start)
+	timing.token(start.Add(300 * time.Millisecond))
+	timing.token(start.Add(320 * time.Millisecond))
+	timing.token(start.Add(380 * time.Millisecond))
+	timing.end(start.Add(400 * time.Millisecond))
+
+	assert.Equal(t, 300*time.Millisecond, timing.TimeToFirstToken)
+	assert.Equal(t, 40*time.Millisecond, timing.MeanInterTokenLatency)
+	assert.Equal(t, 60*time.Millisecond, timing.MaxInterTokenLatency)
+	assert.Equal(t, 400*time.Millisecond, timing.Total)
+	assert.Equal(t, 3, timing.Tokens)
+
+	// Timings that weren't requested are ignored.
+	var none *ResponseTiming
+	none.begin(start)
+	none.token(start)
+	none.end(start)
+}
+
+func TestHTMLResponse(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "content type", contentType: "text/html; charset=utf-8", body: "<html><body>Bad Gateway</body></html>"},
+		{name: "leading bytes", body: "<!DOCTYPE html><html></html>"},
+		{name: "mislabeled", contentType: "application/json", body: "<html></html>"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mockdesktop.NewMockDockerHttpClient(ctrl)
+			mockContext := NewContextForMock(mockClient)
+			client := New(mockContext)
+
+			header := http.Header{}
+			if tc.contentType != "" {
+				header.Set("Content-Type", tc.contentType)
+			}
+			mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(bytes.NewBufferString(tc.body)),
+			}, nil)
+
+			_, err := client.List()
+			assert.EqualError(t, err, "expected JSON from runner but got text/html; check MODEL_RUNNER_HOST")
+		})
+	}
+}
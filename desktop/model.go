@@ -0,0 +1,127 @@
+package desktop
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/docker/model-distribution/types"
+)
+
+// modelFields is used to decode and encode the known fields of a Model
+// without recursing into its custom JSON methods.
+type modelFields Model
+
+// UnmarshalJSON decodes a model, keeping the fields that Model and its config
+// don't know about in Extra and ConfigExtra.
+func (m *Model) UnmarshalJSON(data []byte) error {
+	var model modelFields
+	if err := json.Unmarshal(data, &model); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	model.Extra = unknownFields(fields, reflect.TypeFor[modelFields]())
+	if config, ok := fields["config"]; ok {
+		var configFields map[string]json.RawMessage
+		if err := json.Unmarshal(config, &configFields); err == nil {
+			model.ConfigExtra = unknownFields(configFields, reflect.TypeFor[types.Config]())
+		}
+	}
+	*m = Model(model)
+	return nil
+}
+
+// MarshalJSON encodes a model, including the fields in Extra and ConfigExtra.
+func (m Model) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(modelFields(m))
+	if err != nil || (len(m.Extra) == 0 && len(m.ConfigExtra) == 0) {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range m.Extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	if len(m.ConfigExtra) > 0 {
+		var configFields map[string]json.RawMessage
+		if err := json.Unmarshal(fields["config"], &configFields); err != nil {
+			return nil, err
+		}
+		for key, value := range m.ConfigExtra {
+			if _, ok := configFields[key]; !ok {
+				configFields[key] = value
+			}
+		}
+		if fields["config"], err = json.Marshal(configFields); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UnknownFields returns the sorted names of the fields in Extra and
+// ConfigExtra, with the latter prefixed by "config.".
+func (m Model) UnknownFields() []string {
+	var names []string
+	for key := range m.Extra {
+		names = append(names, key)
+	}
+	for key := range m.ConfigExtra {
+		names = append(names, "config."+key)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// unknownFields returns the entries of fields that don't correspond to a
+// field of the struct type t, or nil if there are none.
+func unknownFields(fields map[string]json.RawMessage, t reflect.Type) map[string]json.RawMessage {
+	known := jsonFieldNames(t)
+	var unknown map[string]json.RawMessage
+	for key, value := range fields {
+		if known[key] {
+			continue
+		}
+		if unknown == nil {
+			unknown = make(map[string]json.RawMessage)
+		}
+		unknown[key] = value
+	}
+	return unknown
+}
+
+// jsonFieldNames returns the names under which encoding/json encodes the
+// fields of the struct type t, including those of embedded structs.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embedded := range jsonFieldNames(field.Type) {
+				names[embedded] = true
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
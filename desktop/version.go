@@ -1,3 +1,8 @@
 package desktop
 
-var Version = "dev"
+// Build metadata, injected at build time via -ldflags "-X ...".
+var (
+	Version   = "dev"
+	GitCommit = ""
+	BuildDate = ""
+)
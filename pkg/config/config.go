@@ -0,0 +1,256 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	cliconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/go-units"
+)
+
+const (
+	// stateDirName is the name of the directory, relative to the Docker CLI
+	// configuration directory, in which the Model CLI stores its state.
+	stateDirName = "model-cli"
+	// configFileName is the name of the settings file within the state
+	// directory.
+	configFileName = "config.json"
+)
+
+// Supported setting keys.
+const (
+	KeyDefaultModel   = "default-model"
+	KeyDefaultBackend = "default-backend"
+	KeyDefaultHost    = "default-host"
+	KeyProgressStyle  = "progress-style"
+
+	KeyResponseCacheTTL = "response-cache-ttl"
+
+	KeyScanAllowedArchitectures = "scan-allowed-architectures"
+	KeyScanMaxSize              = "scan-max-size"
+)
+
+// Progress styles accepted by the progress-style setting.
+const (
+	ProgressStyleAuto  = "auto"
+	ProgressStyleTTY   = "tty"
+	ProgressStylePlain = "plain"
+)
+
+// Config holds persistent Model CLI settings. Settings act as fallbacks and are
+// overridden by flags and environment variables.
+type Config struct {
+	// DefaultModel is the model used by commands when none is specified.
+	DefaultModel string `json:"default-model,omitempty"`
+	// DefaultBackend is the backend used when --backend is not specified.
+	DefaultBackend string `json:"default-backend,omitempty"`
+	// DefaultHost is the model runner endpoint used when MODEL_RUNNER_HOST is
+	// not set.
+	DefaultHost string `json:"default-host,omitempty"`
+	// ProgressStyle controls how transfer progress is rendered.
+	ProgressStyle string `json:"progress-style,omitempty"`
+	// ResponseCacheTTL, if set, enables the run response cache by default and
+	// controls how long cached responses are kept.
+	ResponseCacheTTL string `json:"response-cache-ttl,omitempty"`
+	// ScanAllowedArchitectures is a comma-separated list of the model
+	// architectures accepted by scan.
+	ScanAllowedArchitectures string `json:"scan-allowed-architectures,omitempty"`
+	// ScanMaxSize is the largest model size accepted by scan.
+	ScanMaxSize string `json:"scan-max-size,omitempty"`
+}
+
+// setting describes a single configurable key.
+type setting struct {
+	// field returns a pointer to the setting's value within a Config.
+	field func(c *Config) *string
+	// validate, if non-nil, checks a value before it is stored.
+	validate func(value string) error
+}
+
+var settings = map[string]setting{
+	KeyDefaultModel: {
+		field: func(c *Config) *string { return &c.DefaultModel },
+	},
+	KeyDefaultBackend: {
+		field: func(c *Config) *string { return &c.DefaultBackend },
+	},
+	KeyDefaultHost: {
+		field:    func(c *Config) *string { return &c.DefaultHost },
+		validate: validateHost,
+	},
+	KeyProgressStyle: {
+		field:    func(c *Config) *string { return &c.ProgressStyle },
+		validate: validateProgressStyle,
+	},
+	KeyResponseCacheTTL: {
+		field:    func(c *Config) *string { return &c.ResponseCacheTTL },
+		validate: validateDuration,
+	},
+	KeyScanAllowedArchitectures: {
+		field: func(c *Config) *string { return &c.ScanAllowedArchitectures },
+	},
+	KeyScanMaxSize: {
+		field:    func(c *Config) *string { return &c.ScanMaxSize },
+		validate: validateSize,
+	},
+}
+
+// Keys returns the supported setting keys in sorted order.
+func Keys() []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func lookup(key string) (setting, error) {
+	s, ok := settings[key]
+	if !ok {
+		return setting{}, fmt.Errorf("unknown setting %q (supported: %s)", key, strings.Join(Keys(), ", "))
+	}
+	return s, nil
+}
+
+// ValidateKey returns an error if key is not a supported setting.
+func ValidateKey(key string) error {
+	_, err := lookup(key)
+	return err
+}
+
+// Get returns the value of the specified setting, or an empty string if it is
+// not set.
+func (c *Config) Get(key string) (string, error) {
+	s, err := lookup(key)
+	if err != nil {
+		return "", err
+	}
+	return *s.field(c), nil
+}
+
+// Set updates the value of the specified setting.
+func (c *Config) Set(key, value string) error {
+	s, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("value for %s must not be empty", key)
+	}
+	if s.validate != nil {
+		if err := s.validate(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	*s.field(c) = value
+	return nil
+}
+
+// Unset clears the specified setting.
+func (c *Config) Unset(key string) error {
+	s, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	*s.field(c) = ""
+	return nil
+}
+
+func validateHost(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("expected an http:// or https:// URL (got %q)", value)
+	}
+	return nil
+}
+
+func validateProgressStyle(value string) error {
+	switch value {
+	case ProgressStyleAuto, ProgressStyleTTY, ProgressStylePlain:
+		return nil
+	default:
+		return fmt.Errorf("must be one of: %s, %s, %s (got %q)",
+			ProgressStyleAuto, ProgressStyleTTY, ProgressStylePlain, value)
+	}
+}
+
+func validateDuration(value string) error {
+	if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+		return fmt.Errorf("expected a positive duration such as 24h (got %q)", value)
+	}
+	return nil
+}
+
+func validateSize(value string) error {
+	if _, err := units.RAMInBytes(value); err != nil {
+		return fmt.Errorf("expected a size such as 4GB (got %q)", value)
+	}
+	return nil
+}
+
+// Dir returns the directory in which the Model CLI stores its state.
+func Dir() string {
+	return filepath.Join(cliconfig.Dir(), stateDirName)
+}
+
+// Path returns the path of the settings file.
+func Path() string {
+	return filepath.Join(Dir(), configFileName)
+}
+
+// Load reads the settings file. A missing file yields an empty
+// configuration.
+func Load() (*Config, error) {
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("unable to read configuration: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", Path(), err)
+	}
+	return &cfg, nil
+}
+
+// Save writes the settings file, creating the state directory if necessary.
+// The file is replaced atomically so that concurrent readers never observe a
+// partially written configuration.
+func (c *Config) Save() error {
+	data, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error marshaling configuration: %w", err)
+	}
+	if err := os.MkdirAll(Dir(), 0o700); err != nil {
+		return fmt.Errorf("unable to create configuration directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(Dir(), configFileName+".*")
+	if err != nil {
+		return fmt.Errorf("unable to write configuration: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write configuration: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write configuration: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), Path()); err != nil {
+		return fmt.Errorf("unable to write configuration: %w", err)
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/containerd/errdefs"
+	cliconfig "github.com/docker/cli/cli/config"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/mount"
@@ -24,22 +25,47 @@ import (
 	"github.com/docker/model-cli/pkg/types"
 )
 
-// controllerContainerName is the name to use for the controller container.
-const controllerContainerName = "docker-model-runner"
+// TreatDesktopAsMobyEnv is the environment variable that, when set to 1,
+// makes Docker Desktop contexts behave like Docker Engine ones and get a
+// standalone model runner. This is only intended for testing.
+const TreatDesktopAsMobyEnv = "_MODEL_RUNNER_TREAT_DESKTOP_AS_MOBY"
+
+// TreatDesktopAsMoby returns true if Docker Desktop should be treated like
+// Docker Engine; see TreatDesktopAsMobyEnv.
+func TreatDesktopAsMoby() bool {
+	return os.Getenv(TreatDesktopAsMobyEnv) == "1"
+}
+
+// ControllerContainerName is the default name to use for the controller
+// container.
+const ControllerContainerName = "docker-model-runner"
 
 // copyDockerConfigToContainer copies the Docker config file from the host to the container
-// and sets up proper ownership and permissions for the modelrunner user.
+// and sets up proper ownership and permissions for the modelrunner user. If
+// registriesConfig is set, that file is copied instead of the Docker CLI's.
 // It does nothing for Desktop and Cloud engine kinds.
-func copyDockerConfigToContainer(ctx context.Context, dockerClient *client.Client, containerID string, engineKind types.ModelRunnerEngineKind) error {
+func copyDockerConfigToContainer(ctx context.Context, dockerClient *client.Client, containerID string, engineKind types.ModelRunnerEngineKind, registriesConfig string) error {
 	// Do nothing for Desktop and Cloud engine kinds
 	if engineKind == types.ModelRunnerEngineKindDesktop || engineKind == types.ModelRunnerEngineKindCloud ||
-		os.Getenv("_MODEL_RUNNER_TREAT_DESKTOP_AS_MOBY") == "1" {
+		TreatDesktopAsMoby() {
 		return nil
 	}
 
-	dockerConfigPath := os.ExpandEnv("$HOME/.docker/config.json")
-	if s, err := os.Stat(dockerConfigPath); err != nil || s.Mode()&os.ModeType != 0 {
-		return nil
+	// An explicitly specified registries config must exist. Otherwise, use
+	// the Docker CLI configuration directory so that --config and
+	// DOCKER_CONFIG overrides are respected.
+	dockerConfigPath := registriesConfig
+	if dockerConfigPath != "" {
+		if s, err := os.Stat(dockerConfigPath); err != nil {
+			return fmt.Errorf("unable to use registries config: %w", err)
+		} else if !s.Mode().IsRegular() {
+			return fmt.Errorf("unable to use registries config: %s is not a regular file", dockerConfigPath)
+		}
+	} else {
+		dockerConfigPath = filepath.Join(cliconfig.Dir(), cliconfig.ConfigFileName)
+		if s, err := os.Stat(dockerConfigPath); err != nil || s.Mode()&os.ModeType != 0 {
+			return nil
+		}
 	}
 
 	configData, err := os.ReadFile(dockerConfigPath)
@@ -87,6 +113,40 @@ func copyDockerConfigToContainer(ctx context.Context, dockerClient *client.Clien
 	return nil
 }
 
+// controllerDockerConfigPath is the path of the Docker config file that
+// copyDockerConfigToContainer copies into the controller container.
+const controllerDockerConfigPath = "/home/modelrunner/.docker/config.json"
+
+// ReadControllerDockerConfig reads back the Docker config file that was copied
+// into a controller container. It returns nil if the container has no such
+// file.
+func ReadControllerDockerConfig(ctx context.Context, dockerClient client.ContainerAPIClient, containerID string) ([]byte, error) {
+	content, _, err := dockerClient.CopyFromContainer(ctx, containerID, controllerDockerConfigPath)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to copy config file from container: %w", err)
+	}
+	defer content.Close()
+	tr := tar.NewReader(content)
+	if _, err := tr.Next(); err != nil {
+		return nil, fmt.Errorf("failed to read tar header: %w", err)
+	}
+	data, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config data from tar: %w", err)
+	}
+	return data, nil
+}
+
+// RefreshControllerDockerConfig copies the Docker config file into a running
+// controller container again, e.g. after the credentials on the host were
+// renewed. It does nothing for Desktop and Cloud engine kinds.
+func RefreshControllerDockerConfig(ctx context.Context, dockerClient *client.Client, containerID string, engineKind types.ModelRunnerEngineKind, registriesConfig string) error {
+	return copyDockerConfigToContainer(ctx, dockerClient, containerID, engineKind, registriesConfig)
+}
+
 func execInContainer(ctx context.Context, dockerClient *client.Client, containerID, cmd string) error {
 	execConfig := container.ExecOptions{
 		Cmd: []string{"sh", "-c", cmd},
@@ -128,12 +188,13 @@ func execInContainer(ctx context.Context, dockerClient *client.Client, container
 	}
 }
 
-// FindControllerContainer searches for a running controller container. It
+// FindControllerContainer searches for a running controller container. If name
+// is non-empty, only the controller container with that name is considered. It
 // returns the ID of the container (if found), the container name (if any), the
 // full container summary (if found), or any error that occurred.
-func FindControllerContainer(ctx context.Context, dockerClient client.ContainerAPIClient) (string, string, container.Summary, error) {
+func FindControllerContainer(ctx context.Context, dockerClient client.ContainerAPIClient, name string) (string, string, container.Summary, error) {
 	// Before listing, prune any stopped controller containers.
-	if err := PruneControllerContainers(ctx, dockerClient, true, NoopPrinter()); err != nil {
+	if err := PruneControllerContainers(ctx, dockerClient, true, "", NoopPrinter()); err != nil {
 		return "", "", container.Summary{}, fmt.Errorf("unable to prune stopped model runner containers: %w", err)
 	}
 
@@ -149,14 +210,16 @@ func FindControllerContainer(ctx context.Context, dockerClient client.ContainerA
 	if err != nil {
 		return "", "", container.Summary{}, fmt.Errorf("unable to identify model runner containers: %w", err)
 	}
-	if len(containers) == 0 {
-		return "", "", container.Summary{}, nil
-	}
-	var containerName string
-	if len(containers[0].Names) > 0 {
-		containerName = strings.TrimPrefix(containers[0].Names[0], "/")
+	for _, ctr := range containers {
+		var containerName string
+		if len(ctr.Names) > 0 {
+			containerName = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		if name == "" || containerName == name {
+			return ctr.ID, containerName, ctr, nil
+		}
 	}
-	return containers[0].ID, containerName, containers[0], nil
+	return "", "", container.Summary{}, nil
 }
 
 // determineBridgeGatewayIP attempts to identify the engine's host gateway IP
@@ -215,15 +278,40 @@ func ensureContainerStarted(ctx context.Context, dockerClient client.ContainerAP
 	return errors.New("timed out")
 }
 
-// CreateControllerContainer creates and starts a controller container.
-func CreateControllerContainer(ctx context.Context, dockerClient *client.Client, port uint16, environment string, doNotTrack bool, gpu gpupkg.GPUSupport, modelStorageVolume string, printer StatusPrinter, engineKind types.ModelRunnerEngineKind) error {
+// CreateControllerContainer creates and starts a controller container. If
+// registriesConfig is set, it is used as the runner's Docker config file for
+// registry credentials instead of the host's. If networkName is set, the
+// container is attached to that network instead of the default one.
+// extraLabels are added to the container's labels; see CheckExtraLabels. If
+// containerName is empty, ControllerContainerName is used.
+func CreateControllerContainer(ctx context.Context, dockerClient *client.Client, containerName string, port uint16, environment string, doNotTrack bool, gpu gpupkg.GPUSupport, modelStorageVolume string, printer StatusPrinter, engineKind types.ModelRunnerEngineKind, registriesConfig, networkName string, extraLabels map[string]string) error {
+	if containerName == "" {
+		containerName = ControllerContainerName
+	}
+	if err := CheckExtraLabels(extraLabels); err != nil {
+		return err
+	}
+
 	// Determine the target image.
-	var imageName string
-	switch gpu {
-	case gpupkg.GPUSupportCUDA:
-		imageName = ControllerImage + ":" + controllerImageTagCUDA()
-	default:
-		imageName = ControllerImage + ":" + controllerImageTagCPU()
+	imageName, err := controllerImageName(gpu)
+	if err != nil {
+		return err
+	}
+
+	// Ensure that the requested network exists before creating anything.
+	var networkingConfig *network.NetworkingConfig
+	if networkName != "" {
+		if _, err := dockerClient.NetworkInspect(ctx, networkName, network.InspectOptions{}); err != nil {
+			if errdefs.IsNotFound(err) {
+				return fmt.Errorf("network %s not found", networkName)
+			}
+			return fmt.Errorf("failed to inspect network %s: %w", networkName, err)
+		}
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				networkName: {},
+			},
+		}
 	}
 
 	// Set up the container configuration.
@@ -246,6 +334,9 @@ func CreateControllerContainer(ctx context.Context, dockerClient *client.Client,
 			labelRole:           roleController,
 		},
 	}
+	for key, value := range extraLabels {
+		config.Labels[key] = value
+	}
 	hostConfig := &container.HostConfig{
 		Mounts: []mount.Mount{
 			{
@@ -258,8 +349,11 @@ func CreateControllerContainer(ctx context.Context, dockerClient *client.Client,
 			Name: "always",
 		},
 	}
+	if networkName != "" {
+		hostConfig.NetworkMode = container.NetworkMode(networkName)
+	}
 	portBindings := []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: portStr}}
-	if os.Getenv("_MODEL_RUNNER_TREAT_DESKTOP_AS_MOBY") != "1" {
+	if !TreatDesktopAsMoby() {
 		// Don't bind the bridge gateway IP if we're treating Docker Desktop as Moby.
 		if bridgeGatewayIP, err := determineBridgeGatewayIP(ctx, dockerClient); err == nil && bridgeGatewayIP != "" {
 			portBindings = append(portBindings, nat.PortBinding{HostIP: bridgeGatewayIP, HostPort: portStr})
@@ -307,24 +401,24 @@ func CreateControllerContainer(ctx context.Context, dockerClient *client.Client,
 	// pass silently and simply work in conjunction with any concurrent
 	// installers to start the container.
 	// TODO: Remove strings.Contains check once we ensure it's not necessary.
-	resp, err := dockerClient.ContainerCreate(ctx, config, hostConfig, nil, nil, controllerContainerName)
+	resp, err := dockerClient.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, containerName)
 	if err != nil && !(errdefs.IsConflict(err) || strings.Contains(err.Error(), "is already in use by container")) {
-		return fmt.Errorf("failed to create container %s: %w", controllerContainerName, err)
+		return fmt.Errorf("failed to create container %s: %w", containerName, err)
 	}
 	created := err == nil
 
 	// Start the container.
-	printer.Printf("Starting model runner container %s...\n", controllerContainerName)
-	if err := ensureContainerStarted(ctx, dockerClient, controllerContainerName); err != nil {
+	printer.Printf("Starting model runner container %s...\n", containerName)
+	if err := ensureContainerStarted(ctx, dockerClient, containerName); err != nil {
 		if created {
 			_ = dockerClient.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
 		}
-		return fmt.Errorf("failed to start container %s: %w", controllerContainerName, err)
+		return fmt.Errorf("failed to start container %s: %w", containerName, err)
 	}
 
 	// Copy Docker config file if it exists and we're the container creator.
 	if created {
-		if err := copyDockerConfigToContainer(ctx, dockerClient, resp.ID, engineKind); err != nil {
+		if err := copyDockerConfigToContainer(ctx, dockerClient, resp.ID, engineKind, registriesConfig); err != nil {
 			// Log warning but continue - don't fail container creation
 			printer.Printf("Warning: failed to copy Docker config: %v\n", err)
 		}
@@ -333,8 +427,9 @@ func CreateControllerContainer(ctx context.Context, dockerClient *client.Client,
 }
 
 // PruneControllerContainers stops and removes any model runner controller
-// containers.
-func PruneControllerContainers(ctx context.Context, dockerClient client.ContainerAPIClient, skipRunning bool, printer StatusPrinter) error {
+// containers. If name is non-empty, only the controller container with that
+// name is removed.
+func PruneControllerContainers(ctx context.Context, dockerClient client.ContainerAPIClient, skipRunning bool, name string, printer StatusPrinter) error {
 	// Identify all controller containers.
 	containers, err := dockerClient.ContainerList(ctx, container.ListOptions{
 		All: true,
@@ -354,6 +449,9 @@ func PruneControllerContainers(ctx context.Context, dockerClient client.Containe
 		if skipRunning && ctr.State == container.StateRunning {
 			continue
 		}
+		if name != "" && (len(ctr.Names) == 0 || strings.TrimPrefix(ctr.Names[0], "/") != name) {
+			continue
+		}
 		if len(ctr.Names) > 0 {
 			printer.Printf("Removing container %s (%s)...\n", strings.TrimPrefix(ctr.Names[0], "/"), ctr.ID[:12])
 		} else {
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/distribution/reference"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/jsonmessage"
@@ -24,6 +25,41 @@ const (
 	defaultControllerImageTagCUDA = "latest-cuda"
 )
 
+// controllerImageOverrideEnv is the environment variable that can be used to
+// pin the controller image to a specific digest, overriding the tag derived
+// from MODEL_RUNNER_CONTROLLER_VERSION and the GPU support.
+const controllerImageOverrideEnv = "MODEL_RUNNER_CONTROLLER_IMAGE"
+
+// controllerImageOverride returns the controller image pinned via
+// MODEL_RUNNER_CONTROLLER_IMAGE, if any. The reference must include a digest.
+func controllerImageOverride() (string, error) {
+	override, ok := os.LookupEnv(controllerImageOverrideEnv)
+	if !ok || override == "" {
+		return "", nil
+	}
+	named, err := reference.ParseNormalizedNamed(override)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q: %w", controllerImageOverrideEnv, override, err)
+	}
+	if _, ok := named.(reference.Canonical); !ok {
+		return "", fmt.Errorf("invalid %s %q: the reference must include a digest (e.g. %s@sha256:...)",
+			controllerImageOverrideEnv, override, ControllerImage)
+	}
+	return override, nil
+}
+
+// controllerImageName returns the controller image to use for the given GPU
+// support.
+func controllerImageName(gpu gpupkg.GPUSupport) (string, error) {
+	if override, err := controllerImageOverride(); err != nil || override != "" {
+		return override, err
+	}
+	if gpu == gpupkg.GPUSupportCUDA {
+		return ControllerImage + ":" + controllerImageTagCUDA(), nil
+	}
+	return ControllerImage + ":" + controllerImageTagCPU(), nil
+}
+
 func controllerImageTagCPU() string {
 	if version, ok := os.LookupEnv("MODEL_RUNNER_CONTROLLER_VERSION"); ok && version != "" {
 		return version
@@ -41,12 +77,9 @@ func controllerImageTagCUDA() string {
 // EnsureControllerImage ensures that the controller container image is pulled.
 func EnsureControllerImage(ctx context.Context, dockerClient client.ImageAPIClient, gpu gpupkg.GPUSupport, printer StatusPrinter) error {
 	// Determine the target image.
-	var imageName string
-	switch gpu {
-	case gpupkg.GPUSupportCUDA:
-		imageName = ControllerImage + ":" + controllerImageTagCUDA()
-	default:
-		imageName = ControllerImage + ":" + controllerImageTagCPU()
+	imageName, err := controllerImageName(gpu)
+	if err != nil {
+		return err
 	}
 
 	// Perform the pull.
@@ -90,5 +123,12 @@ func PruneControllerImages(ctx context.Context, dockerClient client.ImageAPIClie
 	if _, err := dockerClient.ImageRemove(ctx, imageNameCUDA, image.RemoveOptions{}); err == nil {
 		printer.Println("Removed image", imageNameCUDA)
 	}
+
+	// Remove the pinned image, if any.
+	if override, err := controllerImageOverride(); err == nil && override != "" {
+		if _, err := dockerClient.ImageRemove(ctx, override, image.RemoveOptions{}); err == nil {
+			printer.Println("Removed image", override)
+		}
+	}
 	return nil
 }
@@ -1,5 +1,7 @@
 package standalone
 
+import "fmt"
+
 const (
 	// labelDesktopService is the label used to identify a container or image as
 	// a Docker Desktop service component. This causes the object to be hidden
@@ -26,4 +28,20 @@ const (
 	// roleModelStorage is the role label value used to identify the model
 	// runner model storage volume.
 	roleModelStorage = "model-storage"
+
+	// labelInstance is the label used to identify the model runner container
+	// that a model storage volume belongs to. It's only set on the volumes of
+	// containers that don't use the default name.
+	labelInstance = "com.docker.model-runner.instance"
 )
+
+// CheckExtraLabels verifies that user-supplied labels don't override the
+// labels used to identify model runner objects.
+func CheckExtraLabels(labels map[string]string) error {
+	for key := range labels {
+		if key == labelDesktopService || key == labelRole || key == labelInstance {
+			return fmt.Errorf("label %s is reserved for use by Docker Model Runner", key)
+		}
+	}
+	return nil
+}
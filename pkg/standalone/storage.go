@@ -0,0 +1,141 @@
+package standalone
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// modelStoragePath is where the model storage volume is mounted in the
+// controller container. The store keeps the manifest of each model under
+// manifests/ and its layers under blobs/, both keyed by digest.
+const modelStoragePath = "/models"
+
+// execInController runs a command in the controller container and returns its
+// output and exit code.
+func execInController(ctx context.Context, dockerClient client.ContainerAPIClient, containerID string, cmd []string) (stdout, stderr string, exitCode int, err error) {
+	execResp, err := dockerClient.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to create exec for %s: %w", cmd[0], err)
+	}
+	attachResp, err := dockerClient.ContainerExecAttach(ctx, execResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to attach to exec for %s: %w", cmd[0], err)
+	}
+	defer attachResp.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&outBuf, &errBuf, attachResp.Reader); err != nil {
+		return "", "", 0, fmt.Errorf("failed to read %s output: %w", cmd[0], err)
+	}
+	inspectResp, err := dockerClient.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to inspect exec for %s: %w", cmd[0], err)
+	}
+	return outBuf.String(), errBuf.String(), inspectResp.ExitCode, nil
+}
+
+// storedPath returns the path of a manifest or blob in the model storage.
+func storedPath(dir string, digest v1.Hash) string {
+	return path.Join(modelStoragePath, dir, digest.Algorithm, digest.Hex)
+}
+
+// StoredManifest reads the raw manifest of a model from the model storage of
+// the specified controller container. id is the model's ID, which is the
+// digest of its manifest.
+func StoredManifest(ctx context.Context, dockerClient client.ContainerAPIClient, containerID, id string) ([]byte, error) {
+	digest, err := v1.NewHash(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model ID %q: %w", id, err)
+	}
+	stdout, stderr, exitCode, err := execInController(ctx, dockerClient, containerID, []string{"cat", storedPath("manifests", digest)})
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("unable to read the manifest of %s: %s", id, strings.TrimSpace(stderr))
+	}
+	return []byte(stdout), nil
+}
+
+// LayerVerification is the verification result for a single model layer.
+type LayerVerification struct {
+	Digest string `json:"digest"`
+	Valid  bool   `json:"valid"`
+	Error  string `json:"error,omitempty"`
+}
+
+// VerifyStoredLayers checks the digests of the stored blobs of the layers of a
+// manifest in the model storage of the specified controller container. Model
+// layers are stored uncompressed, so their blobs are keyed by the digests in
+// the manifest.
+func VerifyStoredLayers(ctx context.Context, dockerClient client.ContainerAPIClient, containerID string, manifest *v1.Manifest) ([]LayerVerification, error) {
+	if len(manifest.Layers) == 0 {
+		return nil, nil
+	}
+	cmd := []string{"sha256sum"}
+	for _, layer := range manifest.Layers {
+		if layer.Digest.Algorithm != "sha256" {
+			return nil, fmt.Errorf("unsupported digest algorithm of layer %s", layer.Digest)
+		}
+		cmd = append(cmd, storedPath("blobs", layer.Digest))
+	}
+	// sha256sum fails if any blob is missing, but still reports the others.
+	stdout, stderr, _, err := execInController(ctx, dockerClient, containerID, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseSHA256Sums(manifest.Layers, stdout, stderr), nil
+}
+
+// parseSHA256Sums matches the output of sha256sum for the blobs of layers
+// against their digests. Blobs that sha256sum couldn't read are reported with
+// its error message.
+func parseSHA256Sums(layers []v1.Descriptor, stdout, stderr string) []LayerVerification {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	for scanner.Scan() {
+		if sum, file, ok := strings.Cut(scanner.Text(), "  "); ok {
+			sums[file] = sum
+		}
+	}
+	var errorLines []string
+	scanner = bufio.NewScanner(strings.NewReader(stderr))
+	for scanner.Scan() {
+		errorLines = append(errorLines, scanner.Text())
+	}
+
+	results := make([]LayerVerification, 0, len(layers))
+	for _, layer := range layers {
+		file := storedPath("blobs", layer.Digest)
+		result := LayerVerification{Digest: layer.Digest.String()}
+		if sum, ok := sums[file]; ok {
+			result.Valid = sum == layer.Digest.Hex
+			if !result.Valid {
+				result.Error = "digest mismatch: sha256:" + sum
+			}
+		} else {
+			result.Error = "blob is missing"
+			for _, line := range errorLines {
+				if strings.Contains(line, file) {
+					result.Error = strings.TrimSpace(line[strings.LastIndex(line, ":")+1:])
+					break
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
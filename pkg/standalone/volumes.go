@@ -3,19 +3,42 @@ package standalone
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 )
 
-// modelStorageVolumeName is the name to use for the model storage volume.
-const modelStorageVolumeName = "docker-model-runner-models"
+// defaultModelStorageVolumeName is the name to use for the model storage
+// volume of the default controller container.
+const defaultModelStorageVolumeName = "docker-model-runner-models"
 
-// EnsureModelStorageVolume ensures that a model storage volume exists, creating
-// it if necessary. It returns the name of the storage volume or any error that
-// occurred.
-func EnsureModelStorageVolume(ctx context.Context, dockerClient client.VolumeAPIClient, printer StatusPrinter) (string, error) {
+// modelStorageInstance returns the instance label value for the model storage
+// of the controller container with the specified name, which is empty for the
+// default container.
+func modelStorageInstance(name string) string {
+	if name == ControllerContainerName {
+		return ""
+	}
+	return name
+}
+
+// modelStorageVolumeName returns the name to use for the model storage volume
+// of the controller container with the specified name.
+func modelStorageVolumeName(name string) string {
+	if instance := modelStorageInstance(name); instance != "" {
+		return instance + "-models"
+	}
+	return defaultModelStorageVolumeName
+}
+
+// EnsureModelStorageVolume ensures that a model storage volume exists for the
+// controller container with the specified name (or the default container if
+// name is empty), creating it if necessary. It returns the name of the storage
+// volume or any error that occurred.
+func EnsureModelStorageVolume(ctx context.Context, dockerClient client.VolumeAPIClient, name string, printer StatusPrinter) (string, error) {
 	// Try to identify the storage volume.
 	volumes, err := dockerClient.VolumeList(ctx, volume.ListOptions{
 		Filters: filters.NewArgs(
@@ -26,20 +49,28 @@ func EnsureModelStorageVolume(ctx context.Context, dockerClient client.VolumeAPI
 		return "", fmt.Errorf("unable to list volumes: %w", err)
 	}
 
-	// If any volumes with the correct role exist (ideally there should only be
-	// one), then pick the first one.
-	if len(volumes.Volumes) > 0 {
-		return volumes.Volumes[0].Name, nil
+	// If any volumes with the correct role and instance exist (ideally there
+	// should only be one), then pick the first one.
+	instance := modelStorageInstance(name)
+	for _, v := range volumes.Volumes {
+		if v.Labels[labelInstance] == instance {
+			return v.Name, nil
+		}
 	}
 
 	// Create the volume.
-	printer.Printf("Creating model storage volume %s...\n", modelStorageVolumeName)
+	labels := map[string]string{
+		labelDesktopService: serviceModelRunner,
+		labelRole:           roleModelStorage,
+	}
+	if instance != "" {
+		labels[labelInstance] = instance
+	}
+	volumeName := modelStorageVolumeName(name)
+	printer.Printf("Creating model storage volume %s...\n", volumeName)
 	volume, err := dockerClient.VolumeCreate(ctx, volume.CreateOptions{
-		Name: modelStorageVolumeName,
-		Labels: map[string]string{
-			labelDesktopService: serviceModelRunner,
-			labelRole:           roleModelStorage,
-		},
+		Name:   volumeName,
+		Labels: labels,
 	})
 	if err != nil {
 		return "", fmt.Errorf("unable to create volume: %w", err)
@@ -47,12 +78,22 @@ func EnsureModelStorageVolume(ctx context.Context, dockerClient client.VolumeAPI
 	return volume.Name, nil
 }
 
-// PruneModelStorageVolumes removes any unused model storage volume(s).
-func PruneModelStorageVolumes(ctx context.Context, dockerClient client.VolumeAPIClient, printer StatusPrinter) error {
-	pruned, err := dockerClient.VolumesPrune(ctx, filters.NewArgs(
+// PruneModelStorageVolumes removes any unused model storage volume(s). If name
+// is non-empty, only the volume of the controller container with that name is
+// removed.
+func PruneModelStorageVolumes(ctx context.Context, dockerClient client.VolumeAPIClient, name string, printer StatusPrinter) error {
+	args := filters.NewArgs(
 		filters.Arg("all", "true"),
 		filters.Arg("label", labelRole+"="+roleModelStorage),
-	))
+	)
+	if name != "" {
+		if instance := modelStorageInstance(name); instance != "" {
+			args.Add("label", labelInstance+"="+instance)
+		} else {
+			args.Add("label!", labelInstance)
+		}
+	}
+	pruned, err := dockerClient.VolumesPrune(ctx, args)
 	if err != nil {
 		return err
 	}
@@ -64,3 +105,46 @@ func PruneModelStorageVolumes(ctx context.Context, dockerClient client.VolumeAPI
 	}
 	return nil
 }
+
+// StorageCapacity describes the filesystem that backs model storage.
+type StorageCapacity struct {
+	// Total is the total size of the filesystem in bytes.
+	Total uint64
+	// Available is the space available on the filesystem in bytes.
+	Available uint64
+}
+
+// ModelStorageCapacity determines the capacity of the filesystem backing the
+// model storage mount of the specified controller container.
+func ModelStorageCapacity(ctx context.Context, dockerClient client.ContainerAPIClient, containerID string) (StorageCapacity, error) {
+	stdout, stderr, exitCode, err := execInController(ctx, dockerClient, containerID, []string{"df", "-P", "-k", modelStoragePath})
+	if err != nil {
+		return StorageCapacity{}, err
+	}
+	if exitCode != 0 {
+		return StorageCapacity{}, fmt.Errorf("df failed with exit code %d: %s", exitCode, strings.TrimSpace(stderr))
+	}
+	return parseDFOutput(stdout)
+}
+
+// parseDFOutput parses the output of "df -P -k" for a single filesystem.
+func parseDFOutput(output string) (StorageCapacity, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return StorageCapacity{}, fmt.Errorf("unexpected df output: %q", output)
+	}
+	// Filesystem 1024-blocks Used Available Capacity Mounted-on
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return StorageCapacity{}, fmt.Errorf("unexpected df output: %q", output)
+	}
+	total, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return StorageCapacity{}, fmt.Errorf("unexpected df output: %q", output)
+	}
+	available, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return StorageCapacity{}, fmt.Errorf("unexpected df output: %q", output)
+	}
+	return StorageCapacity{Total: total * 1024, Available: available * 1024}, nil
+}